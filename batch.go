@@ -0,0 +1,31 @@
+package multitemplate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// BatchRender implements Render.BatchRender and DynamicRender.BatchRender.
+func (r *registry) BatchRender(name string, items []interface{}, sink func(i int, r io.Reader) error) error {
+	tmpl, err := r.executable(nil, name)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i, data := range items {
+		if r.opts.dataHook != nil {
+			data = r.opts.dataHook(nil, name, data)
+		}
+
+		buf.Reset()
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("render item %d: %w", i, err)
+		}
+		if err := sink(i, &buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
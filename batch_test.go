@@ -0,0 +1,32 @@
+package multitemplate
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchRender(t *testing.T) {
+	r := createFromStringDynamic().(testRenderer)
+
+	var pages []string
+	err := r.BatchRender("index", []interface{}{gin.H{"name": "a"}, gin.H{"name": "b"}}, func(_ int, r io.Reader) error {
+		b, err := io.ReadAll(r)
+		pages = append(pages, string(b))
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Welcome to a template", "Welcome to b template"}, pages)
+
+	errSink := errors.New("sink failed")
+	err = r.BatchRender("index", []interface{}{nil, nil}, func(i int, _ io.Reader) error {
+		assert.Equal(t, 0, i)
+		return errSink
+	})
+	assert.ErrorIs(t, err, errSink)
+
+	assert.ErrorIs(t, r.BatchRender("missing", nil, nil), ErrTemplateNotFound)
+}
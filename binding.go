@@ -0,0 +1,180 @@
+package multitemplate
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+	"weak"
+)
+
+// binding ties a renderer map to its registry. The registry never refers to
+// the map, so the map can be garbage collected, which drops the binding.
+type binding struct {
+	m   weak.Pointer[byte]
+	reg *registry
+}
+
+// registries holds the binding of every Render and DynamicRender map, keyed
+// by the address of the map.
+var registries sync.Map
+
+// mapPointer returns the address of the renderer map m.
+func mapPointer[M Render | DynamicRender](m M) *byte {
+	return (*byte)(reflect.ValueOf(m).UnsafePointer())
+}
+
+// bind makes reg the registry of the renderer map m until m is garbage
+// collected and returns m. If another goroutine bound m first, reg is dropped.
+func bind[M Render | DynamicRender](m M, reg *registry) M {
+	bindRegistry(m, reg)
+	return m
+}
+
+// bindRegistry works like bind and returns the registry bound to m.
+func bindRegistry[M Render | DynamicRender](m M, reg *registry) *registry {
+	p := mapPointer(m)
+	key := uintptr(unsafe.Pointer(p))
+	b := &binding{m: weak.Make(p), reg: reg}
+	for {
+		v, loaded := registries.LoadOrStore(key, b)
+		if loaded {
+			old := v.(*binding)
+			if old.m.Value() == p {
+				return old.reg
+			}
+			// a collected map lived at the same address
+			if !registries.CompareAndSwap(key, old, b) {
+				continue
+			}
+		}
+		runtime.AddCleanup(p, func(key uintptr) { registries.CompareAndDelete(key, b) }, key)
+		return reg
+	}
+}
+
+// bound returns the registry bound to the renderer map m, or nil.
+func bound[M Render | DynamicRender](m M) *registry {
+	p := mapPointer(m)
+	v, ok := registries.Load(uintptr(unsafe.Pointer(p)))
+	if !ok {
+		return nil
+	}
+	if b := v.(*binding); b.m.Value() == p {
+		return b.reg
+	}
+	return nil
+}
+
+// registry returns the registry of r. A map not created with New, e.g. a
+// Render literal, is bound to a registry with the default options when it
+// is first used. Templates stored into the map directly are registered as if
+// added with Add on the next call that sees the map grow.
+func (r Render) registry() *registry {
+	if r == nil {
+		return newRegistry(false, nil)
+	}
+	reg := bound(r)
+	if reg == nil {
+		reg = bindRegistry(r, newRegistry(false, nil))
+	}
+	reg.mirrorTemplates(r)
+	return reg
+}
+
+// registry returns the registry of r, see Render.registry.
+func (r DynamicRender) registry() *registry {
+	if r == nil {
+		return newRegistry(true, nil)
+	}
+	reg := bound(r)
+	if reg == nil {
+		reg = bindRegistry(r, newRegistry(true, nil))
+	}
+	reg.mirrorBuilders(r)
+	return reg
+}
+
+// isMirrored reports whether the renderer map, whose length n returns,
+// reflects the builders, so there is nothing to mirror. n is called with
+// mirrorMu held as the mirror methods write the map.
+func (r *registry) isMirrored(n func() int) bool {
+	r.mirrorMu.RLock()
+	defer r.mirrorMu.RUnlock()
+	return n() == r.mirrorLen && r.gen.Load() == r.mirrored
+}
+
+// mirrorTemplates registers the templates stored into m, the Render map of
+// a static registry, since the last call and stores the html/template
+// templates of the builders into m.
+func (r *registry) mirrorTemplates(m Render) {
+	if r.isMirrored(func() int { return len(m) }) {
+		return
+	}
+
+	r.mirrorMu.Lock()
+	defer r.mirrorMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(m) != r.mirrorLen {
+		for name, tmpl := range m {
+			if builder, ok := r.builders[name]; tmpl == nil || ok && builder.tmpl == tmpl {
+				continue
+			}
+			r.builders[name] = &templateBuilder{
+				buildType:    templateType,
+				templateName: name,
+				tmpl:         tmpl,
+				options:      *NewTemplateOptions(),
+				settings:     &r.opts,
+			}
+		}
+	}
+	for name := range m {
+		if builder, ok := r.builders[name]; !ok || builder.tmpl == nil {
+			delete(m, name)
+		}
+	}
+	for name, builder := range r.builders {
+		if builder.tmpl != nil {
+			m[name] = builder.tmpl
+		}
+	}
+	r.mirrored = r.gen.Load()
+	r.mirrorLen = len(m)
+}
+
+// mirrorBuilders works like mirrorTemplates for m, the DynamicRender map of
+// a dynamic registry.
+func (r *registry) mirrorBuilders(m DynamicRender) {
+	if r.isMirrored(func() int { return len(m) }) {
+		return
+	}
+
+	r.mirrorMu.Lock()
+	defer r.mirrorMu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(m) != r.mirrorLen {
+		for name, builder := range m {
+			if current, ok := r.builders[name]; builder == nil || ok && current == builder {
+				continue
+			}
+			imported := *builder
+			imported.settings = &r.opts
+			r.builders[name] = &imported
+		}
+	}
+	for name := range m {
+		if _, ok := r.builders[name]; !ok {
+			delete(m, name)
+		}
+	}
+	for name, builder := range r.builders {
+		m[name] = builder
+	}
+	r.mirrored = r.gen.Load()
+	r.mirrorLen = len(m)
+}
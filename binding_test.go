@@ -0,0 +1,35 @@
+package multitemplate
+
+import (
+	"html/template"
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryFreedWithMap(t *testing.T) {
+	key := func() uintptr {
+		r := New()
+		r.AddFromString("index", "Welcome")
+		return uintptr(unsafe.Pointer(mapPointer(r)))
+	}()
+
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		_, ok := registries.Load(key)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRenderDirectWrite(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "Welcome")
+	r["about"] = template.Must(template.New("about").Parse("About"))
+
+	assert.True(t, r.Exists("about"))
+	assert.ElementsMatch(t, []string{"about", "index"}, r.Names())
+	assert.Same(t, r["index"], r.registry().builders["index"].tmpl)
+}
@@ -0,0 +1,156 @@
+package multitemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+var htmlContentType = []string{"text/html; charset=utf-8"}
+
+// plainContentType is the Content-Type of the plain text templates, see AddTextFromString.
+const plainContentType = "text/plain; charset=utf-8"
+
+// bufferedRender executes the template into a buffer and only writes the
+// response once execution succeeded, so a failed render never sends partial output.
+type bufferedRender struct {
+	template executor
+	// name of the associated template to execute instead of the root one.
+	name string
+	// blocks are associated templates executed in order instead of name.
+	blocks  []renderBlock
+	data    interface{}
+	timeout time.Duration
+	// pretty re-indents text/html output.
+	pretty bool
+	// minify collapses the whitespace of text/html output.
+	minify bool
+	// postProcess transforms the output before it is written.
+	postProcess func(out []byte) ([]byte, error)
+	// store keeps the output in the output cache.
+	store func(out []byte)
+	// failed records render errors, see RecentErrors.
+	failed func(err error)
+	// contentType replaces the HTML Content-Type if set.
+	contentType string
+	// serverTiming adds the execution duration to the Server-Timing header.
+	serverTiming bool
+}
+
+var _ render.Render = bufferedRender{}
+
+// Render (bufferedRender) executes the template and writes the result into the response body.
+func (r bufferedRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	start := time.Now()
+	out, err := r.output(w)
+	if err != nil {
+		if r.failed != nil {
+			r.failed(err)
+		}
+		if errors.Is(err, ErrRenderTimeout) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		return err
+	}
+	if r.serverTiming {
+		ms := float64(time.Since(start).Microseconds()) / 1000
+		w.Header().Add("Server-Timing", fmt.Sprintf("render;dur=%.1f", ms))
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// output executes the template and transforms the result into the body to write.
+func (r bufferedRender) output(w http.ResponseWriter) ([]byte, error) {
+	buf, err := r.execute()
+	if err != nil {
+		return nil, err
+	}
+
+	out := buf.Bytes()
+	if r.pretty && strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		if out, err = indentHTML(out); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.minify && strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		if out, err = minifyHTML(out); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.postProcess != nil {
+		if out, err = r.postProcess(out); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.store != nil {
+		r.store(out)
+	}
+	return out, nil
+}
+
+// WriteContentType (bufferedRender) writes HTML ContentType.
+func (r bufferedRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		if r.contentType != "" {
+			header["Content-Type"] = []string{r.contentType}
+		} else {
+			header["Content-Type"] = htmlContentType
+		}
+	}
+}
+
+func (r bufferedRender) execute() (*bytes.Buffer, error) {
+	if r.timeout <= 0 {
+		buf := new(bytes.Buffer)
+		return buf, r.executeTo(buf)
+	}
+
+	done := make(chan error, 1)
+	buf := new(bytes.Buffer)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("template execution panicked: %v", p)
+			}
+		}()
+		done <- r.executeTo(buf)
+	}()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return buf, err
+	case <-timer.C:
+		return nil, ErrRenderTimeout
+	}
+}
+
+func (r bufferedRender) executeTo(w io.Writer) error {
+	if len(r.blocks) > 0 {
+		for _, block := range r.blocks {
+			if err := block.execute(w, r.template, r.data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if r.name == "" {
+		return r.template.Execute(w, r.data)
+	}
+	return r.template.ExecuteTemplate(w, r.name, r.data)
+}
@@ -0,0 +1,119 @@
+package multitemplate
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceTimeout(t *testing.T) {
+	r := New()
+	r.AddFromStringsFuncs("slow", template.FuncMap{
+		"sleep": func(d time.Duration) string {
+			time.Sleep(d)
+			return "done"
+		},
+	}, `partial {{ sleep .d }}`)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceTimeout("slow", gin.H{"d": 50 * time.Millisecond}, 10*time.Millisecond))
+	})
+	w := performRequest(router)
+	assert.Equal(t, 503, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	router = gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceTimeout("slow", gin.H{"d": time.Duration(0)}, time.Second))
+	})
+	w = performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "partial done", w.Body.String())
+}
+
+func TestSetTimeout(t *testing.T) {
+	r := New()
+	r.AddFromStringsFuncs("slow", template.FuncMap{
+		"sleep": func(d time.Duration) string {
+			time.Sleep(d)
+			return "done"
+		},
+	}, `partial {{ sleep .d }}`)
+	r.SetTimeout("slow", 10*time.Millisecond)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "slow", gin.H{"d": 50 * time.Millisecond})
+	})
+	w := performRequest(router)
+	assert.Equal(t, 503, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	r.SetTimeout("slow", 0)
+	w = performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "partial done", w.Body.String())
+}
+
+func TestPostProcessor(t *testing.T) {
+	r := New(
+		WithPostProcessor(func(name string, out []byte) ([]byte, error) {
+			return append([]byte(name+": "), out...), nil
+		}),
+		WithPostProcessor(func(_ string, out []byte) ([]byte, error) {
+			return bytes.ToUpper(out), nil
+		}),
+	)
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "index", gin.H{"name": "index"})
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, "INDEX: WELCOME TO INDEX TEMPLATE", w.Body.String())
+}
+
+func TestPostProcessorError(t *testing.T) {
+	errProcess := errors.New("process failed")
+	r := New(WithPostProcessor(func(string, []byte) ([]byte, error) {
+		return nil, errProcess
+	}))
+	r.AddFromString("index", "Welcome")
+
+	w := httptest.NewRecorder()
+	err := r.Instance("index", nil).Render(w)
+	assert.ErrorIs(t, err, errProcess)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestWithServerTiming(t *testing.T) {
+	r := New(WithServerTiming())
+	r.AddFromString("index", `Welcome to {{ .name }} template`)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Header("Server-Timing", "db;dur=3")
+		c.HTML(200, "index", gin.H{"name": "timed"})
+	})
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "Welcome to timed template", w.Body.String())
+
+	timings := w.Header().Values("Server-Timing")
+	assert.Len(t, timings, 2)
+	assert.Equal(t, "db;dur=3", timings[0])
+	assert.Regexp(t, `^render;dur=\d+\.\d$`, timings[1])
+}
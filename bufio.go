@@ -0,0 +1,47 @@
+package multitemplate
+
+import (
+	"bufio"
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// bufioRender collects the writes of the wrapped render in a bufio.Writer, so
+// templates made of many small fragments are written in few large writes.
+type bufioRender struct {
+	wrapped render.Render
+	size    int
+}
+
+// bufioResponseWriter writes the body through a bufio.Writer.
+type bufioResponseWriter struct {
+	http.ResponseWriter
+	buf *bufio.Writer
+}
+
+func (w bufioResponseWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+// Render (bufioRender) writes the wrapped render through a buffer and flushes it.
+func (r bufioRender) Render(w http.ResponseWriter) error {
+	buf := bufio.NewWriterSize(w, r.size)
+	if err := r.wrapped.Render(bufioResponseWriter{ResponseWriter: w, buf: buf}); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// WriteContentType (bufioRender) writes the ContentType of the wrapped render.
+func (r bufioRender) WriteContentType(w http.ResponseWriter) {
+	r.wrapped.WriteContentType(w)
+}
+
+// bufio wraps rr to buffer its writes if the renderer is configured to.
+func (r *registry) bufio(rr render.Render) render.Render {
+	if r.opts.writeBufferSize <= 0 {
+		return rr
+	}
+	return bufioRender{wrapped: rr, size: r.opts.writeBufferSize}
+}
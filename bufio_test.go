@@ -0,0 +1,40 @@
+package multitemplate
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingRecorder counts the writes made to the response body.
+type countingRecorder struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *countingRecorder) Write(b []byte) (int, error) {
+	w.writes++
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestWithWriteBuffer(t *testing.T) {
+	page := strings.Repeat("<li>{{ .name }}</li>", 100)
+	for _, tt := range []struct {
+		renderer testRenderer
+		writes   int
+	}{
+		{New(), 201},
+		{New(WithWriteBuffer(64 * 1024)), 1},
+	} {
+		tt.renderer.AddFromString("index", page)
+
+		w := &countingRecorder{ResponseRecorder: httptest.NewRecorder()}
+		err := tt.renderer.Instance("index", gin.H{"name": "item"}).Render(w)
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("<li>item</li>", 100), w.Body.String())
+		assert.Equal(t, tt.writes, w.writes)
+	}
+}
@@ -0,0 +1,82 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// FuncBundle groups related template functions, e.g. the money helpers of a
+// shop, so they are registered together with the bundles they build on.
+type FuncBundle struct {
+	// Name identifies the bundle in errors. Bundles are merged once per name.
+	Name  string
+	Funcs template.FuncMap
+	// Requires are the bundles merged before this one, e.g. a rounding
+	// bundle whose round function templates call on the result of formatMoney.
+	Requires []FuncBundle
+	// Needs names functions the templates using the bundle expect that are
+	// neither in Funcs nor in Requires but registered by another bundle.
+	Needs []string
+}
+
+// collect merges the functions of b into funcMap, the bundles it requires
+// first, skipping the bundles named in merged.
+func (b FuncBundle) collect(funcMap template.FuncMap, merged map[string]bool) {
+	if b.Name != "" {
+		if merged[b.Name] {
+			return
+		}
+		merged[b.Name] = true
+	}
+	for _, required := range b.Requires {
+		required.collect(funcMap, merged)
+	}
+	for name, fn := range b.Funcs {
+		funcMap[name] = fn
+	}
+}
+
+// missing returns the sorted functions needed by b and the bundles it
+// requires that funcMap does not define.
+func (b FuncBundle) missing(funcMap template.FuncMap) []string {
+	seen := make(map[string]bool)
+	var missing []string
+	var check func(b FuncBundle)
+	check = func(b FuncBundle) {
+		for _, required := range b.Requires {
+			check(required)
+		}
+		for _, name := range b.Needs {
+			if _, ok := funcMap[name]; !ok && !builtinFuncs[name] && !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+		}
+	}
+	check(b)
+	sort.Strings(missing)
+	return missing
+}
+
+// UseBundle implements Render.UseBundle and DynamicRender.UseBundle.
+func (r *registry) UseBundle(b FuncBundle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	funcMap := template.FuncMap{}
+	for name, fn := range r.opts.bundleFuncs {
+		funcMap[name] = fn
+	}
+	merged := make(map[string]bool, len(r.opts.bundles))
+	for name := range r.opts.bundles {
+		merged[name] = true
+	}
+	b.collect(funcMap, merged)
+	if missing := b.missing(funcMap); len(missing) > 0 {
+		panic(fmt.Sprintf("func bundle %s needs undefined functions: %s", b.Name, strings.Join(missing, ", ")))
+	}
+	r.opts.bundleFuncs = funcMap
+	r.opts.bundles = merged
+}
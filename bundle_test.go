@@ -0,0 +1,43 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseBundle(t *testing.T) {
+	rounding := FuncBundle{
+		Name:  "rounding",
+		Funcs: template.FuncMap{"round": math.Round},
+	}
+	money := FuncBundle{
+		Name:     "money",
+		Funcs:    template.FuncMap{"formatMoney": func(v float64) string { return fmt.Sprintf("$%.2f", v) }},
+		Requires: []FuncBundle{rounding},
+	}
+	tax := FuncBundle{
+		Name:  "tax",
+		Funcs: template.FuncMap{"vat": func(v float64) float64 { return v * 0.2 }},
+		Needs: []string{"formatMoney", "round"},
+	}
+
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		assert.PanicsWithValue(t, "func bundle tax needs undefined functions: formatMoney, round", func() {
+			r.UseBundle(tax)
+		})
+
+		r.UseBundle(money)
+		r.UseBundle(tax)
+		r.AddFromString("index", `{{ formatMoney (round .price) }} {{ formatMoney (vat .price) }}`)
+
+		w, err := renderResponse(r, "index", gin.H{"price": 9.6})
+		assert.NoError(t, err)
+		assert.Equal(t, "$10.00 $1.92", w.Body.String())
+		assert.Contains(t, r.Funcs("index"), "round")
+	}
+}
@@ -0,0 +1,62 @@
+package multitemplate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// checksum returns the hex encoded SHA-256 of the names and contents of the
+// source files of the builder, or "" if it has none.
+func (tb templateBuilder) checksum() (string, error) {
+	sources, err := tb.sources()
+	if err != nil {
+		return "", err
+	}
+	return sourcesChecksum(sources), nil
+}
+
+// sourcesChecksum returns the checksum of sources, see checksum.
+func sourcesChecksum(sources []templateSource) string {
+	if len(sources) == 0 {
+		return ""
+	}
+
+	h := sha256.New()
+	for _, source := range sources {
+		fmt.Fprintf(h, "%s\x00%d\x00%s", source.name, len(source.content), source.content)
+		if source.meta != nil {
+			fmt.Fprintf(h, "\x00%v", source.meta)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// keptSum returns the checksum of the sources of the kept template, or "" if
+// there is none.
+func (tb templateBuilder) keptSum() string {
+	if tb.buildType == fsGlobTemplateType && tb.lazy != nil {
+		return tb.lazy.checksum()
+	}
+	return tb.sum
+}
+
+// HasChanged implements Render.HasChanged and DynamicRender.HasChanged.
+func (r *registry) HasChanged(name string) (bool, error) {
+	r.mu.RLock()
+	builder, ok := r.builders[name]
+	dynamic := r.dynamic
+	r.mu.RUnlock()
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	if dynamic && !builder.pinned {
+		return true, nil
+	}
+
+	sum, err := builder.checksum()
+	if err != nil {
+		return false, err
+	}
+	return sum != builder.keptSum(), nil
+}
@@ -0,0 +1,89 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// Functions called at the start and end of every template by WithMaxDepth.
+const (
+	enterFunc = "_multitemplate_enter"
+	leaveFunc = "_multitemplate_leave"
+)
+
+// depthFuncs returns the functions tracking the include depth of a single
+// render, failing with ErrMaxDepth past max levels.
+func depthFuncs(max int) template.FuncMap {
+	depth := 0
+	return template.FuncMap{
+		enterFunc: func() (bool, error) {
+			depth++
+			if depth > max {
+				return false, fmt.Errorf("%w of %d", ErrMaxDepth, max)
+			}
+			return false, nil
+		},
+		leaveFunc: func() bool {
+			depth--
+			return false
+		},
+	}
+}
+
+// limitDepth wraps the body of every template in the set of tmpl between
+// calls to the depth functions, see WithMaxDepth. The calls are if actions,
+// so they never write output nor change the escaping context.
+func (tb templateBuilder) limitDepth(tmpl executor) error {
+	if tb.settings == nil || tb.settings.maxDepth <= 0 {
+		return nil
+	}
+	for _, tree := range templateTrees(tmpl) {
+		if tree == nil || tree.Root == nil || isDepthCall(tree.Root.Nodes, enterFunc) {
+			continue
+		}
+		enter, err := depthCall(enterFunc)
+		if err != nil {
+			return err
+		}
+		leave, err := depthCall(leaveFunc)
+		if err != nil {
+			return err
+		}
+		tree.Root.Nodes = append(append([]parse.Node{enter}, tree.Root.Nodes...), leave)
+	}
+
+	// the functions are bound per render, see registry.executable
+	funcs := depthFuncs(tb.settings.maxDepth)
+	switch t := tmpl.(type) {
+	case *template.Template:
+		t.Funcs(funcs)
+	case *texttemplate.Template:
+		t.Funcs(texttemplate.FuncMap(funcs))
+	}
+	return nil
+}
+
+// depthCall returns an {{if fn}}{{end}} node calling the depth function fn.
+func depthCall(fn string) (parse.Node, error) {
+	trees, err := parse.Parse("depth", "{{if "+fn+"}}{{end}}", "{{", "}}",
+		map[string]interface{}{fn: true})
+	if err != nil {
+		return nil, err
+	}
+	return trees["depth"].Root.Nodes[0], nil
+}
+
+// isDepthCall reports whether nodes start with a call to the depth function fn.
+func isDepthCall(nodes []parse.Node, fn string) bool {
+	if len(nodes) == 0 {
+		return false
+	}
+	n, ok := nodes[0].(*parse.IfNode)
+	if !ok || len(n.Pipe.Cmds) != 1 {
+		return false
+	}
+	ident, ok := n.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == fn
+}
@@ -0,0 +1,39 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMaxDepth(t *testing.T) {
+	tree := `{{define "node"}}<li>{{.Name}}{{range .Children}}<ul>{{template "node" .}}</ul>{{end}}</li>{{end}}` +
+		`{{template "node" .}}`
+	deep := gin.H{"Name": "a", "Children": []gin.H{{"Name": "b", "Children": []gin.H{{"Name": "c"}}}}}
+	wide := gin.H{"Name": "a", "Children": []gin.H{{"Name": "b"}, {"Name": "c"}, {"Name": "d"}}}
+
+	for _, r := range []testRenderer{New(WithMaxDepth(3)), NewDynamic(WithMaxDepth(3))} {
+		r.AddFromString("tree", tree)
+		r.AddTrustedFromString("text", tree)
+
+		w, err := renderResponse(r, "tree", wide)
+		assert.NoError(t, err)
+		assert.Equal(t, "<li>a<ul><li>b</li></ul><ul><li>c</li></ul><ul><li>d</li></ul></li>", w.Body.String())
+
+		_, err = renderResponse(r, "tree", deep)
+		assert.ErrorIs(t, err, ErrMaxDepth)
+		_, err = renderResponse(r, "text", deep)
+		assert.ErrorIs(t, err, ErrMaxDepth)
+
+		used, err := r.UsedFuncs("tree")
+		assert.NoError(t, err)
+		assert.Empty(t, used)
+	}
+
+	r := New(WithMaxDepth(4))
+	r.AddFromString("tree", tree)
+	w, err := renderResponse(r, "tree", deep)
+	assert.NoError(t, err)
+	assert.Equal(t, "<li>a<ul><li>b<ul><li>c</li></ul></li></ul></li>", w.Body.String())
+}
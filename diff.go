@@ -0,0 +1,45 @@
+package multitemplate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+)
+
+// renderBytes executes the template registered under name with data.
+func (r *registry) renderBytes(name string, data interface{}) ([]byte, error) {
+	tmpl, err := r.executable(nil, name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderHash implements Render.RenderHash and DynamicRender.RenderHash.
+func (r *registry) RenderHash(name string, data interface{}) (string, error) {
+	out, err := r.renderBytes(name, data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(out)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DiffRenders implements Render.DiffRenders and DynamicRender.DiffRenders.
+func (r *registry) DiffRenders(name string, data interface{}, oldTemplate *template.Template) (bool, error) {
+	out, err := r.renderBytes(name, data)
+	if err != nil {
+		return false, err
+	}
+	var old bytes.Buffer
+	if err := oldTemplate.Execute(&old, data); err != nil {
+		return false, fmt.Errorf("render previous template %s: %w", name, err)
+	}
+	return !bytes.Equal(out, old.Bytes()), nil
+}
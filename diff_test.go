@@ -0,0 +1,59 @@
+package multitemplate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRenders(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, os.WriteFile(file, []byte("Hello {{ .name }}"), 0o600))
+
+	r := New()
+	r.AddFromFiles("index", file)
+	fixture := gin.H{"name": "fixture"}
+
+	hash, err := r.RenderHash("index", fixture)
+	assert.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	old, err := r.Build("index")
+	assert.NoError(t, err)
+	assert.NoError(t, r.ReloadAll())
+	changed, err := r.DiffRenders("index", fixture, old)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	assert.NoError(t, os.WriteFile(file, []byte("Bye {{ .name }}"), 0o600))
+	assert.NoError(t, r.ReloadAll())
+	changed, err = r.DiffRenders("index", fixture, old)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	newHash, err := r.RenderHash("index", fixture)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, newHash)
+
+	_, err = r.RenderHash("missing", nil)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestDiffRendersCloneOptions(t *testing.T) {
+	r := New(WithMaxDepth(3))
+	r.AddFromString("index", "Hello {{ .name }}")
+	fixture := gin.H{"name": "fixture"}
+
+	old, err := r.Build("index")
+	assert.NoError(t, err)
+	changed, err := r.DiffRenders("index", fixture, old)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	w, err := renderResponse(r, "index", fixture)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello fixture", w.Body.String())
+}
@@ -0,0 +1,100 @@
+package multitemplate
+
+import (
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// dirFiles returns the regular files below root whose base name matches
+// pattern (all files if pattern is empty), keyed by their slash separated
+// path relative to root.
+func dirFiles(root, pattern string) (map[string]string, error) {
+	names, err := walkFS(os.DirFS(root), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, len(names))
+	for name := range names {
+		if pattern != "" {
+			matched, err := path.Match(pattern, path.Base(name))
+			if err != nil {
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		files[name] = filepath.Join(root, filepath.FromSlash(name))
+	}
+	return files, nil
+}
+
+// AddFromDirFuncs supply add every file below root whose base name matches pattern
+// (e.g. "*.html") as its own template parsed with funcMap, registered under its path
+// relative to root
+func (r Render) AddFromDirFuncs(root, pattern string, funcMap template.FuncMap) {
+	files, err := dirFiles(root, pattern)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range sortedNames(files) {
+		r.AddFromFilesFuncs(name, funcMap, files[name])
+	}
+}
+
+// AddAllFromFSFuncs supply add every file below root in fs.FS (e.g. embed.FS) as its own
+// template parsed with funcMap, registered under its path relative to root
+func (r Render) AddAllFromFSFuncs(fsys fs.FS, root string, funcMap template.FuncMap) {
+	files, err := walkFS(fsys, root)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range sortedNames(files) {
+		r.add(name, &templateBuilder{
+			buildType:    fsFuncTemplateType,
+			templateName: filepath.Base(files[name]),
+			funcMap:      funcMap,
+			fsys:         fsys,
+			files:        []string{files[name]},
+			options:      *NewTemplateOptions(),
+			literal:      true,
+		})
+	}
+}
+
+// AddFromDirFuncs supply add every file below root whose base name matches pattern
+// (e.g. "*.html") as its own template parsed with funcMap, registered under its path
+// relative to root
+func (r DynamicRender) AddFromDirFuncs(root, pattern string, funcMap template.FuncMap) {
+	files, err := dirFiles(root, pattern)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range sortedNames(files) {
+		r.AddFromFilesFuncs(name, funcMap, files[name])
+	}
+}
+
+// AddAllFromFSFuncs supply add every file below root in fs.FS (e.g. embed.FS) as its own
+// template parsed with funcMap, registered under its path relative to root
+func (r DynamicRender) AddAllFromFSFuncs(fsys fs.FS, root string, funcMap template.FuncMap) {
+	files, err := walkFS(fsys, root)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range sortedNames(files) {
+		builder := &templateBuilder{
+			templateName: filepath.Base(files[name]),
+			funcMap:      funcMap,
+			fsys:         fsys,
+			files:        []string{files[name]},
+			literal:      true,
+		}
+		builder.buildType = fsFuncTemplateType
+		r.add(name, builder)
+	}
+}
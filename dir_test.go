@@ -0,0 +1,49 @@
+package multitemplate
+
+import (
+	"html/template"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddFromDirFuncs(t *testing.T) {
+	funcMap := template.FuncMap{"upper": strings.ToUpper}
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromDirFuncs("tests/dir", "*.html", funcMap)
+		assert.Equal(t, []string{"index.html", "partials/sidebar.html"}, r.Names())
+
+		w, err := renderResponse(r, "partials/sidebar.html", gin.H{"name": "dir"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<aside>DIR</aside>", w.Body.String())
+	}
+
+	assert.Panics(t, func() { New().AddFromDirFuncs("tests/missing", "*.html", funcMap) })
+	assert.Panics(t, func() { New().AddFromDirFuncs("tests/dir", "[", funcMap) })
+}
+
+func TestAddAllFromFSFuncs(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddAllFromFSFuncs(os.DirFS("tests/dir"), "partials", template.FuncMap{"upper": strings.ToUpper})
+		assert.Equal(t, []string{"sidebar.html"}, r.Names())
+
+		w, err := renderResponse(r, "sidebar.html", gin.H{"name": "fs"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<aside>FS</aside>", w.Body.String())
+	}
+}
+
+func TestAddAllFromFSFuncsPatternNames(t *testing.T) {
+	fsys := fstest.MapFS{"pages/[id].html": {Data: []byte("Item {{ upper .id }}")}}
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddAllFromFSFuncs(fsys, "pages", template.FuncMap{"upper": strings.ToUpper})
+
+		w, err := renderResponse(r, "[id].html", gin.H{"id": "a1"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Item A1", w.Body.String())
+	}
+}
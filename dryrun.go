@@ -0,0 +1,36 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	texttemplate "text/template"
+)
+
+// DryRun implements Render.DryRun and DynamicRender.DryRun.
+func (r *registry) DryRun(name string, data interface{}) error {
+	r.mu.RLock()
+	builder, ok := r.builders[name]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	tmpl, err := builder.buildExecutor()
+	if err != nil {
+		return err
+	}
+	shared := builder.buildType == templateType || builder.buildType == lazyTemplateType
+	switch t := tmpl.(type) {
+	case *template.Template:
+		if shared {
+			if t, err = t.Clone(); err != nil {
+				return err
+			}
+		}
+		tmpl = t.Option("missingkey=error")
+	case *texttemplate.Template:
+		tmpl = t.Option("missingkey=error")
+	}
+	return newTemplateError(tmpl.Execute(io.Discard, data))
+}
@@ -5,6 +5,8 @@ import (
 	"html/template"
 	"io/fs"
 	"path/filepath"
+	texttemplate "text/template"
+	"text/template/parse"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/render"
@@ -19,17 +21,17 @@ var (
 )
 
 // NewDynamic is the constructor for Dynamic templates
-func NewDynamic() DynamicRender {
-	return make(DynamicRender)
+func NewDynamic(opts ...RendererOption) DynamicRender {
+	return bind(make(DynamicRender), newRegistry(true, opts))
 }
 
 // NewRenderer allows create an agnostic multitemplate renderer
 // depending on enabled gin mode
-func NewRenderer() Renderer {
+func NewRenderer(opts ...RendererOption) Renderer {
 	if gin.IsDebugging() {
-		return NewDynamic()
+		return NewDynamic(opts...)
 	}
-	return New()
+	return New(opts...)
 }
 
 // Type of dynamic builder
@@ -45,9 +47,39 @@ const (
 	stringTemplateType
 	stringFuncTemplateType
 	filesFuncTemplateType
+	sectionsTemplateType
+	lazyTemplateType
+	engineTemplateType
+	zipTemplateType
+	treeTemplateType
+	fsGlobTemplateType
 )
 
-// Builder for dynamic templates
+var builderTypeNames = map[builderType]string{
+	templateType:           "template",
+	filesTemplateType:      "files",
+	globTemplateType:       "glob",
+	fsTemplateType:         "fs",
+	fsFuncTemplateType:     "fs_funcs",
+	stringTemplateType:     "string",
+	stringFuncTemplateType: "string_funcs",
+	filesFuncTemplateType:  "files_funcs",
+	sectionsTemplateType:   "sections",
+	lazyTemplateType:       "lazy",
+	engineTemplateType:     "engine",
+	zipTemplateType:        "zip",
+	treeTemplateType:       "tree",
+	fsGlobTemplateType:     "fs_glob",
+}
+
+func (t builderType) String() string {
+	if name, ok := builderTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("builderType(%d)", int(t))
+}
+
+// Builder for templates, kept by every renderer and rebuilt on demand in dynamic mode
 type templateBuilder struct {
 	buildType       builderType
 	tmpl            *template.Template
@@ -59,48 +91,109 @@ type templateBuilder struct {
 	funcMap         template.FuncMap
 	templateStrings []string
 	options         TemplateOptions
+	settings        *rendererOptions
+	// text templates are parsed with text/template, see AddTrustedFromString.
+	text bool
+	// meta is the front matter kept with the template, see Meta.
+	meta map[string]interface{}
+	// contentType replaces the default Content-Type of the template, see AddTextFromString.
+	contentType string
+	textTmpl    *texttemplate.Template
+	// lazy templates are built by a factory, see AddLazy.
+	lazy *lazyTemplate
+	// engine templates are built by an Engine, see AddFromFilesEngine.
+	engine     func() Engine
+	engineTmpl engineTemplate
+	// literal fs templates name their files by path instead of fs.Glob
+	// patterns, see AddAllFromFS.
+	literal bool
+	// zipPath is the archive of zip templates, see AddFromZip.
+	zipPath string
+	// tree is the parse tree of tree templates, see AddTree.
+	tree *parse.Tree
+	// sum is the checksum of the sources of a kept template, see HasChanged.
+	// It is only computed with WithChecksumReload.
+	sum string
+	// loaded holds the sources read by keep while it builds the template, so
+	// the build and the checksum see the same content.
+	loaded []templateSource
+	// pinned templates of a dynamic renderer are kept like static ones, see Pin.
+	pinned bool
+	// base is a never executed build of a kept template to clone, see InstanceFuncs.
+	base executor
 }
 
 func (tb templateBuilder) buildTemplate() *template.Template {
+	return template.Must(tb.build())
+}
+
+func (tb templateBuilder) build() (*template.Template, error) {
+	tmpl, err := tb.parse()
+	if err != nil {
+		if checkErr := tb.checkParseError(); checkErr != nil {
+			return nil, checkErr
+		}
+		return nil, newTemplateError(err)
+	}
+	if err := tb.checkParsed(tmpl); err != nil {
+		return nil, err
+	}
+	tb.markMissing(tmpl)
+	if err := tb.limitDepth(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func (tb templateBuilder) parse() (*template.Template, error) {
 	switch tb.buildType {
+	case treeTemplateType:
+		return tb.parseTree()
+	case lazyTemplateType:
+		return callFactory(tb.templateName, tb.lazy.factory)
 	case templateType:
-		return tb.tmpl.Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter)
-	case filesTemplateType:
-		tmpl := template.Must(template.ParseFiles(tb.files...))
-		return tmpl.Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter)
-	case globTemplateType:
-		tmpl := template.Must(template.ParseGlob(tb.glob))
-		return tmpl.Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter)
-	case fsTemplateType:
-		tmpl := template.Must(template.ParseFS(tb.fsys, tb.files...))
-		return tmpl.Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter)
-	case fsFuncTemplateType:
-		tmpl := template.New(tb.templateName).
-			Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter).
-			Funcs(tb.funcMap)
-		return template.Must(tmpl.ParseFS(tb.fsys, tb.files...))
-	case stringTemplateType:
-		tmpl := template.New(tb.templateName).
-			Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter)
-		return template.Must(tmpl.Parse(tb.templateString))
-	case stringFuncTemplateType:
-		tmpl := template.New(tb.templateName).
-			Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter).
-			Funcs(tb.funcMap)
-		for _, ts := range tb.templateStrings {
-			tmpl = template.Must(tmpl.Parse(ts))
+		return tb.tmpl.Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter), nil
+	case filesTemplateType, filesFuncTemplateType, globTemplateType, fsTemplateType, fsFuncTemplateType,
+		zipTemplateType, fsGlobTemplateType:
+		sources, err := tb.sources()
+		if err != nil {
+			return nil, err
+		}
+		return tb.parseSources(sources)
+	case sectionsTemplateType:
+		sources, err := tb.sources()
+		if err != nil {
+			return nil, err
+		}
+		own := &sources[len(sources)-1]
+		own.content = expandSections(own.content, tb.options)
+		return tb.parseSources(sources)
+	case stringTemplateType, stringFuncTemplateType:
+		texts, err := tb.texts()
+		if err != nil {
+			return nil, err
+		}
+		tmpl := tb.newTemplate(tb.templateName)
+		for _, text := range texts {
+			if _, err := tmpl.Parse(text.content); err != nil {
+				return nil, err
+			}
 		}
-		return tmpl
-	case filesFuncTemplateType:
-		tmpl := template.New(tb.templateName).
-			Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter).
-			Funcs(tb.funcMap)
-		return template.Must(tmpl.ParseFiles(tb.files...))
+		return tmpl, nil
 	default:
-		panic("Invalid builder type for dynamic template")
+		return nil, fmt.Errorf("template %s: %w %s", tb.templateName, ErrInvalidBuilder, tb.buildType)
 	}
 }
 
+// newTemplate allocates a new template with the builder delimiters and
+// every function available to it, ready to be parsed.
+func (tb templateBuilder) newTemplate(name string) *template.Template {
+	return template.New(name).
+		Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter).
+		Funcs(predefinedFuncs).
+		Funcs(tb.funcs())
+}
+
 // Add new template
 func (r DynamicRender) Add(name string, tmpl *template.Template) {
 	if tmpl == nil {
@@ -111,23 +204,68 @@ func (r DynamicRender) Add(name string, tmpl *template.Template) {
 	}
 	builder := &templateBuilder{templateName: name, tmpl: tmpl, options: *NewTemplateOptions()}
 	builder.buildType = templateType
-	r[name] = builder
+	r.register(name, builder)
+}
+
+// add registers builder under name and returns a first build of its template
+func (r DynamicRender) add(name string, builder *templateBuilder) *template.Template {
+	r.register(name, builder)
+	return builder.buildTemplate()
+}
+
+func (r DynamicRender) register(name string, builder *templateBuilder) {
+	reg := r.registry()
+	builder.settings = &reg.opts
+
+	reg.mu.Lock()
+	reg.builders[name] = builder
+	reg.gen.Add(1)
+	reg.mu.Unlock()
+	reg.mirrorBuilders(r)
+}
+
+// Pin builds the named templates once and keeps serving them from that build
+// instead of rebuilding them on every render, e.g. for hot templates whose
+// sources rarely change, while the others keep reloading. ReloadAll rebuilds
+// the pinned templates; adding a template again under a pinned name unpins it.
+// It returns an error, and pins nothing, if a template is not registered or
+// fails to build.
+func (r DynamicRender) Pin(names ...string) error {
+	reg := r.registry()
+	defer reg.mirrorBuilders(r)
+	return reg.pin(names)
+}
+
+// FlushCache drops everything the renderer memoized, e.g. after an asset
+// pipeline regenerated the template files, so the next render parses every
+// template from its sources: the templates pinned with Pin are unpinned and
+// the output cache is cleared. Call Pin again to keep the rebuilt templates.
+func (r DynamicRender) FlushCache() {
+	reg := r.registry()
+	reg.flushCache()
+	reg.mirrorBuilders(r)
 }
 
 // AddFromFiles supply add template from files
 func (r DynamicRender) AddFromFiles(name string, files ...string) *template.Template {
 	builder := &templateBuilder{templateName: name, files: files, options: *NewTemplateOptions()}
 	builder.buildType = filesTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
+}
+
+// AddIf supply add template from files only if cond is true, see Render.AddIf
+func (r DynamicRender) AddIf(cond bool, name string, files ...string) *template.Template {
+	if !cond {
+		return nil
+	}
+	return r.AddFromFiles(name, files...)
 }
 
 // AddFromGlob supply add template from global path
 func (r DynamicRender) AddFromGlob(name, glob string) *template.Template {
 	builder := &templateBuilder{templateName: name, glob: glob, options: *NewTemplateOptions()}
 	builder.buildType = globTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
 }
 
 // AddFromFS adds a new template to the DynamicRender from the provided file system (fs.FS) and files.
@@ -143,8 +281,7 @@ func (r DynamicRender) AddFromGlob(name, glob string) *template.Template {
 func (r DynamicRender) AddFromFS(name string, fsys fs.FS, files ...string) *template.Template {
 	builder := &templateBuilder{templateName: name, fsys: fsys, files: files}
 	builder.buildType = fsTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
 }
 
 // AddFromFSFuncs adds a new template to the DynamicRender from the provided file system (fs.FS) and files.
@@ -172,16 +309,45 @@ func (r DynamicRender) AddFromFSFuncs(
 		files:        files,
 	}
 	builder.buildType = fsFuncTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
+}
+
+// AddFromFSFuncsGlob supply add template from the files of fs.FS (e.g. embed.FS)
+// matching pattern, with callback func
+func (r DynamicRender) AddFromFSFuncsGlob(
+	name string,
+	funcMap template.FuncMap,
+	fsys fs.FS,
+	pattern string,
+) *template.Template {
+	return r.AddFromFSFuncs(name, funcMap, fsys, pattern)
 }
 
 // AddFromString supply add template from strings
 func (r DynamicRender) AddFromString(name, templateString string) *template.Template {
 	builder := &templateBuilder{templateName: name, templateString: templateString, options: *NewTemplateOptions()}
 	builder.buildType = stringTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
+}
+
+// AddFromStringDelims supply add template from strings parsed with the
+// left and right delimiters, overriding the default ones for this template only
+func (r DynamicRender) AddFromStringDelims(name, left, right, templateString string) *template.Template {
+	builder := &templateBuilder{
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(Delims(left, right)),
+	}
+	builder.buildType = stringTemplateType
+	return r.add(name, builder)
+}
+
+// AddFromStringNamed supply add template from strings, registered under key and
+// parsed as the template templateName
+func (r DynamicRender) AddFromStringNamed(key, templateName, templateString string) *template.Template {
+	builder := &templateBuilder{templateName: templateName, templateString: templateString, options: *NewTemplateOptions()}
+	builder.buildType = stringTemplateType
+	return r.add(key, builder)
 }
 
 // AddFromStringsFuncs supply add template from strings
@@ -196,8 +362,7 @@ func (r DynamicRender) AddFromStringsFuncs(
 		options:         *NewTemplateOptions(),
 	}
 	builder.buildType = stringFuncTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
 }
 
 // AddFromStringsFuncsWithOptions supply add template from strings with options
@@ -214,8 +379,7 @@ func (r DynamicRender) AddFromStringsFuncsWithOptions(
 		options:         options,
 	}
 	builder.buildType = stringFuncTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
 }
 
 // AddFromFilesFuncs supply add template from file callback func
@@ -223,8 +387,7 @@ func (r DynamicRender) AddFromFilesFuncs(name string, funcMap template.FuncMap,
 	tname := filepath.Base(files[0])
 	builder := &templateBuilder{templateName: tname, funcMap: funcMap, files: files, options: *NewTemplateOptions()}
 	builder.buildType = filesFuncTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
 }
 
 // AddFromFilesFuncs supply add template from file callback func
@@ -242,18 +405,89 @@ func (r DynamicRender) AddFromFilesFuncsWithOptions(
 		options:      options,
 	}
 	builder.buildType = filesFuncTemplateType
-	r[name] = builder
-	return builder.buildTemplate()
+	return r.add(name, builder)
 }
 
-// Instance supply render string
-func (r DynamicRender) Instance(name string, data interface{}) render.Render {
-	builder, ok := r[name]
-	if !ok {
-		panic(fmt.Sprintf("Dynamic template with name %s not found", name))
+// AddFromFileSections supply add template from a file split into sections by markers
+func (r DynamicRender) AddFromFileSections(name, file string) *template.Template {
+	builder := &templateBuilder{templateName: name, files: []string{file}, options: *NewTemplateOptions()}
+	builder.buildType = sectionsTemplateType
+	return r.add(name, builder)
+}
+
+// AddAllFromFS supply add every file below root in fs.FS (e.g. embed.FS) as its own template,
+// registered under its path relative to root
+func (r DynamicRender) AddAllFromFS(fsys fs.FS, root string) {
+	files, err := walkFS(fsys, root)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range sortedNames(files) {
+		builder := &templateBuilder{templateName: name, fsys: fsys, files: []string{files[name]}, literal: true}
+		builder.buildType = fsTemplateType
+		r.add(name, builder)
 	}
-	return render.HTML{
-		Template: builder.buildTemplate(),
-		Data:     data,
+}
+
+// AddTrustedFromString supply add trusted template from strings, see Render.AddTrustedFromString
+func (r DynamicRender) AddTrustedFromString(name, templateString string) *texttemplate.Template {
+	builder := &templateBuilder{
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(),
+		text:           true,
 	}
+	builder.buildType = stringTemplateType
+	r.register(name, builder)
+	return texttemplate.Must(builder.buildText())
+}
+
+// AddTrustedFromFiles supply add trusted template from files, see Render.AddTrustedFromString
+func (r DynamicRender) AddTrustedFromFiles(name string, files ...string) *texttemplate.Template {
+	builder := &templateBuilder{templateName: name, files: files, options: *NewTemplateOptions(), text: true}
+	builder.buildType = filesTemplateType
+	r.register(name, builder)
+	return texttemplate.Must(builder.buildText())
+}
+
+// AddTrustedFromFS supply add trusted template from fs.FS (e.g. embed.FS), see Render.AddTrustedFromString
+func (r DynamicRender) AddTrustedFromFS(name string, fsys fs.FS, files ...string) *texttemplate.Template {
+	builder := &templateBuilder{
+		templateName: name,
+		fsys:         fsys,
+		files:        files,
+		options:      *NewTemplateOptions(),
+		text:         true,
+	}
+	builder.buildType = fsTemplateType
+	r.register(name, builder)
+	return texttemplate.Must(builder.buildText())
+}
+
+// AddTextFromString supply add plain text template from strings, rendered as text/plain
+func (r DynamicRender) AddTextFromString(name, templateString string) *texttemplate.Template {
+	builder := &templateBuilder{
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(),
+		text:           true,
+		contentType:    plainContentType,
+	}
+	builder.buildType = stringTemplateType
+	r.register(name, builder)
+	return texttemplate.Must(builder.buildText())
+}
+
+// AddTextFromFiles supply add plain text template from files, rendered as text/plain
+func (r DynamicRender) AddTextFromFiles(name string, files ...string) *texttemplate.Template {
+	builder := &templateBuilder{
+		templateName: name,
+		files:        files,
+		options:      *NewTemplateOptions(),
+		text:         true,
+		contentType:  plainContentType,
+	}
+	builder.buildType = filesTemplateType
+	r.register(name, builder)
+	return texttemplate.Must(builder.buildText())
 }
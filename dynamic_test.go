@@ -1,9 +1,13 @@
 package multitemplate
 
 import (
+	"fmt"
 	"html/template"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -166,6 +170,15 @@ func TestPanicInvalidTypeBuilder(t *testing.T) {
 	})
 }
 
+func TestErrInvalidBuilder(t *testing.T) {
+	r := NewDynamic()
+	r["invalid"] = &templateBuilder{buildType: 100, templateName: "invalid"}
+
+	_, err := r.Build("invalid")
+	assert.ErrorIs(t, err, ErrInvalidBuilder)
+	assert.EqualError(t, err, "template invalid: invalid builder type builderType(100)")
+}
+
 func TestTemplateNotFound(t *testing.T) {
 	r := make(DynamicRender)
 	r.AddFromString("index", "This is a test template")
@@ -217,3 +230,274 @@ func TestAddFromFSFuncsDynamic(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Equal(t, "<p>Test Multiple Template</p>\nHi, this is article template\n", w.Body.String())
 }
+
+func TestAddFromFSFuncsGlob(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/index.html":  {Data: []byte(`{{ upper .name }} {{ template "footer" }}`)},
+		"views/layout.html": {Data: []byte(`{{ define "footer" }}{{ upper "footer" }}{{ end }}`)},
+	}
+	funcMap := template.FuncMap{"upper": strings.ToUpper}
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromFSFuncsGlob("index", funcMap, fsys, "views/*.html")
+
+		w, err := renderResponse(r, "index", gin.H{"name": "gin"})
+		assert.NoError(t, err)
+		assert.Equal(t, "GIN FOOTER", w.Body.String())
+	}
+}
+
+func TestMergeDynamic(t *testing.T) {
+	core := NewDynamic()
+	core.AddFromString("index", "Welcome to {{ .name }} template")
+	static := New()
+	static.AddFromString("static", "Static {{ .name }}")
+
+	assert.NoError(t, core.Merge(static))
+	assert.Contains(t, core, "static")
+	assert.Error(t, core.Merge(static))
+
+	router := gin.New()
+	router.HTMLRender = core
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "static", gin.H{"name": "index"})
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "Static index", w.Body.String())
+}
+
+func TestRenderResponseDynamic(t *testing.T) {
+	r := createFromStringDynamic()
+
+	w, err := renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+
+	_, err = renderResponse(r, "NotFoundTemplate", nil)
+	assert.Error(t, err)
+}
+
+func TestWithDataHookDynamic(t *testing.T) {
+	r := NewDynamic(WithDataHook(func(c *gin.Context, _ string, data interface{}) interface{} {
+		h := gin.H{"name": data.(gin.H)["name"]}
+		if c != nil {
+			h["name"] = c.Request.URL.Path
+		}
+		return h
+	}))
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "index", gin.H{"name": "index"})
+	})
+	w := performRequest(router)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+
+	router = gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceCtx(c, "index", gin.H{"name": "index"}))
+	})
+	w = performRequest(router)
+	assert.Equal(t, "Welcome to / template", w.Body.String())
+}
+
+func TestTemplatesForFile(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromFiles("index", "tests/base.html", "tests/article.html")
+	r.AddFromFiles("welcome", "tests/welcome.html", "tests/content.html")
+	r.AddFromGlob("login", "tests/global/*")
+	r.AddFromFS("fs", os.DirFS("."), "tests/base.html", "tests/article.html")
+	r.AddFromString("string", "Welcome to {{ .name }} template")
+
+	assert.Equal(t, []string{"fs", "index"}, r.TemplatesForFile("tests/article.html"))
+	assert.Equal(t, []string{"fs", "index"}, r.TemplatesForFile("./tests/../tests/base.html"))
+	assert.Equal(t, []string{"login"}, r.TemplatesForFile("tests/global/login.html"))
+	assert.Empty(t, r.TemplatesForFile("tests/missing.html"))
+}
+
+func TestWithMissingAsEmptyDynamic(t *testing.T) {
+	for _, tt := range []struct {
+		opt  RendererOption
+		code int
+	}{
+		{WithMissingAsEmpty(), 200},
+		{WithMissingStatus(404), 404},
+	} {
+		r := NewDynamic(tt.opt)
+		r.AddFromString("index", "Welcome to {{ .name }} template")
+
+		w, err := renderResponse(r, "widget", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.code, w.Code)
+		assert.Empty(t, w.Body.String())
+
+		w, err = renderResponse(r, "index", gin.H{"name": "index"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to index template", w.Body.String())
+	}
+}
+
+func TestInstanceEmptyDynamic(t *testing.T) {
+	for _, tt := range []struct {
+		r    DynamicRender
+		code int
+	}{
+		{NewDynamic(), 204},
+		{NewDynamic(WithEmptyStatus(202)), 202},
+	} {
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) {
+			c.Render(200, tt.r.InstanceEmpty(map[string]string{"HX-Trigger": "saved"}))
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, tt.code, w.Code)
+		assert.Equal(t, "saved", w.Header().Get("HX-Trigger"))
+		assert.Empty(t, w.Body.String())
+	}
+}
+
+func TestInstanceEmptyNoContentDynamic(t *testing.T) {
+	r := NewDynamic()
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(204, r.InstanceEmpty(map[string]string{"HX-Trigger": "saved"}))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, "saved", w.Header().Get("HX-Trigger"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestSetCommonFilesDynamic(t *testing.T) {
+	r := NewDynamic()
+	r.SetCommonFiles("tests/common/_helpers.html")
+	r.AddFromFiles("page", "tests/common/page.html", "tests/content.html")
+
+	w, err := renderResponse(r, "page", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello index, template\n", w.Body.String())
+	assert.Equal(t, []string{"page"}, r.TemplatesForFile("tests/common/_helpers.html"))
+}
+
+func TestAddAllFromFSDynamic(t *testing.T) {
+	r := NewDynamic()
+	r.AddAllFromFS(os.DirFS("tests"), "basedir")
+
+	assert.Len(t, r, 2)
+	w, err := renderResponse(r, "partials/sidebar.html", gin.H{"title": "Sidebar"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<aside>Sidebar</aside>", w.Body.String())
+
+	assert.Panics(t, func() {
+		r.AddAllFromFS(os.DirFS("tests"), "missing")
+	})
+}
+
+func TestAddAllFromFSPatternNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/[id].html": {Data: []byte("Item {{ .id }}")},
+		"pages/a?b.html":  {Data: []byte("Query {{ .q }}")},
+	}
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddAllFromFS(fsys, "pages")
+
+		w, err := renderResponse(r, "[id].html", gin.H{"id": 1})
+		assert.NoError(t, err)
+		assert.Equal(t, "Item 1", w.Body.String())
+		w, err = renderResponse(r, "a?b.html", gin.H{"q": "x"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Query x", w.Body.String())
+	}
+}
+
+func TestSetDebugFuncsDynamic(t *testing.T) {
+	r := NewDynamic()
+	r.SetDebugFuncs(template.FuncMap{
+		"debug": func(v interface{}) string { return fmt.Sprintf("%v", v) },
+	})
+	r.AddFromString("index", "Welcome to {{ .name }} template{{ debug .name }}")
+
+	w, err := renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index templateindex", w.Body.String())
+
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.DebugMode)
+	w, err = renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+}
+
+func TestBuildDynamic(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+	r["broken"] = &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   "broken",
+		templateString: "{{ .name ",
+	}
+
+	first, err := r.Build("index")
+	assert.NoError(t, err)
+	second, err := r.Build("index")
+	assert.NoError(t, err)
+	assert.NotSame(t, first, second)
+
+	_, err = r.Build("broken")
+	assert.Error(t, err)
+	_, err = r.Build("missing")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestPinDynamic(t *testing.T) {
+	dir := t.TempDir()
+	hot := filepath.Join(dir, "hot.html")
+	cold := filepath.Join(dir, "cold.html")
+	assert.NoError(t, os.WriteFile(hot, []byte("hot v1"), 0o600))
+	assert.NoError(t, os.WriteFile(cold, []byte("cold v1"), 0o600))
+
+	r := NewDynamic()
+	r.AddFromFiles("hot", hot)
+	r.AddFromFiles("cold", cold)
+	assert.NoError(t, r.Pin("hot"))
+	assert.ErrorIs(t, r.Pin("hot", "missing"), ErrTemplateNotFound)
+
+	assert.NoError(t, os.WriteFile(hot, []byte("hot v2"), 0o600))
+	assert.NoError(t, os.WriteFile(cold, []byte("cold v2"), 0o600))
+
+	render := func(name string) string {
+		rec, err := renderResponse(r, name, nil)
+		assert.NoError(t, err)
+		return rec.Body.String()
+	}
+	assert.Equal(t, "hot v1", render("hot"))
+	assert.Equal(t, "cold v2", render("cold"))
+
+	assert.NoError(t, r.ReloadAll())
+	assert.Equal(t, "hot v2", render("hot"))
+}
+
+func TestFlushCacheDynamic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+
+	r := NewDynamic(WithOutputCache(10))
+	r.AddFromFiles("index", file)
+	assert.NoError(t, r.Pin("index"))
+	assert.NoError(t, os.WriteFile(file, []byte("v2"), 0o600))
+
+	rec, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", rec.Body.String())
+
+	r.FlushCache()
+	rec, err = renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", rec.Body.String())
+	assert.False(t, r["index"].pinned)
+}
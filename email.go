@@ -0,0 +1,223 @@
+package multitemplate
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// simpleSelector matches the selectors the CSS inliner supports: an optional
+// element name followed by any number of #id and .class parts.
+var simpleSelector = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9-]*)?((?:[.#][-_a-zA-Z0-9]+)*)$`)
+
+var (
+	cssComment   = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	selectorPart = regexp.MustCompile(`[.#][^.#]+`)
+)
+
+// cssRule is a style rule with a single supported selector.
+type cssRule struct {
+	tag         string
+	id          string
+	classes     []string
+	specificity int
+	decls       string
+}
+
+func (rule cssRule) matches(n *html.Node) bool {
+	if rule.tag != "" && !strings.EqualFold(rule.tag, n.Data) {
+		return false
+	}
+	if rule.id != "" && attr(n, "id") != rule.id {
+		return false
+	}
+	classes := strings.Fields(attr(n, "class"))
+	for _, class := range rule.classes {
+		found := false
+		for _, c := range classes {
+			if c == class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCSS splits a style sheet into the rules the inliner supports and the
+// remaining CSS, e.g. @media blocks and descendant or pseudo-class selectors.
+func parseCSS(css string) (rules []cssRule, rest string) {
+	css = cssComment.ReplaceAllString(css, "")
+	var remaining strings.Builder
+	for {
+		open := strings.IndexByte(css, '{')
+		if open < 0 {
+			break
+		}
+		prelude := strings.TrimSpace(css[:open])
+		end, depth := open, 0
+		for ; end < len(css); end++ {
+			if css[end] == '{' {
+				depth++
+			} else if css[end] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if end == len(css) {
+			break
+		}
+		block := css[open+1 : end]
+		css = css[end+1:]
+
+		if strings.HasPrefix(prelude, "@") {
+			remaining.WriteString(prelude + "{" + block + "}\n")
+			continue
+		}
+		decls := strings.TrimSpace(block)
+		if decls != "" && !strings.HasSuffix(decls, ";") {
+			decls += ";"
+		}
+		for _, selector := range strings.Split(prelude, ",") {
+			selector = strings.TrimSpace(selector)
+			rule, ok := parseSelector(selector)
+			if !ok {
+				remaining.WriteString(selector + " {" + block + "}\n")
+				continue
+			}
+			rule.decls = decls
+			rules = append(rules, rule)
+		}
+	}
+	return rules, remaining.String()
+}
+
+func parseSelector(selector string) (cssRule, bool) {
+	m := simpleSelector.FindStringSubmatch(selector)
+	if m == nil || selector == "" {
+		return cssRule{}, false
+	}
+	rule := cssRule{tag: m[1]}
+	if rule.tag != "" {
+		rule.specificity = 1
+	}
+	for _, part := range selectorPart.FindAllString(m[2], -1) {
+		if part[0] == '#' {
+			rule.id = part[1:]
+			rule.specificity += 100
+		} else {
+			rule.classes = append(rule.classes, part[1:])
+			rule.specificity += 10
+		}
+	}
+	return rule, true
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// inlineCSS moves the rules of the <style> elements of an HTML document into
+// the style attributes of the elements they match, as email clients ignore
+// style sheets. Rules are applied by specificity then order, and existing
+// style attributes take precedence. CSS the inliner does not support is kept
+// in its <style> element; style elements left empty are removed.
+func inlineCSS(src []byte) ([]byte, error) {
+	doc, err := html.Parse(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []cssRule
+	var styles, elements []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if n.Data == "style" {
+				styles = append(styles, n)
+				return
+			}
+			elements = append(elements, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	for _, style := range styles {
+		var css strings.Builder
+		for c := style.FirstChild; c != nil; c = c.NextSibling {
+			css.WriteString(c.Data)
+		}
+		parsed, rest := parseCSS(css.String())
+		rules = append(rules, parsed...)
+		for style.FirstChild != nil {
+			style.RemoveChild(style.FirstChild)
+		}
+		if rest == "" {
+			style.Parent.RemoveChild(style)
+			continue
+		}
+		style.AppendChild(&html.Node{Type: html.TextNode, Data: rest})
+	}
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].specificity < rules[j].specificity })
+
+	for _, n := range elements {
+		var decls []string
+		for _, rule := range rules {
+			if rule.decls != "" && rule.matches(n) {
+				decls = append(decls, rule.decls)
+			}
+		}
+		if len(decls) == 0 {
+			continue
+		}
+		if inline := strings.TrimSpace(attr(n, "style")); inline != "" {
+			decls = append(decls, inline)
+		}
+		setAttr(n, "style", strings.Join(decls, " "))
+	}
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderEmail implements Render.RenderEmail and DynamicRender.RenderEmail.
+func (r *registry) RenderEmail(name string, data interface{}) (string, error) {
+	out, err := r.renderBytes(name, data)
+	if err != nil {
+		return "", err
+	}
+	out, err = inlineCSS(out)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
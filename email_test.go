@@ -0,0 +1,40 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderEmail(t *testing.T) {
+	r := New()
+	r.AddFromString("receipt", `<html><head><style>
+/* receipt */
+p { color: #333; }
+.total, #grand { font-weight: bold }
+p.note { color: gray; }
+a:hover { color: red; }
+@media (max-width: 600px) { p { font-size: 12px; } }
+</style></head><body>
+<p>Hello {{ .name }}</p>
+<p class="note total" style="margin: 0">Note</p>
+<span id="grand">42</span>
+</body></html>`)
+
+	out, err := r.RenderEmail("receipt", gin.H{"name": "Ann"})
+	assert.NoError(t, err)
+	assert.Contains(t, out, `<p style="color: #333;">Hello Ann</p>`)
+	assert.Contains(t, out, `<p class="note total" style="color: #333; font-weight: bold; color: gray; margin: 0">Note</p>`)
+	assert.Contains(t, out, `<span id="grand" style="font-weight: bold;">42</span>`)
+	assert.Contains(t, out, "a:hover {")
+	assert.Contains(t, out, "@media (max-width: 600px){ p { font-size: 12px; } }")
+
+	r.AddFromString("plain", `<style>p { color: red }</style><p>Hi</p>`)
+	out, err = r.RenderEmail("plain", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `<html><head></head><body><p style="color: red;">Hi</p></body></html>`, out)
+
+	_, err = r.RenderEmail("missing", nil)
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
@@ -0,0 +1,44 @@
+package multitemplate
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// emptyRender writes no body. A non-zero status replaces the status chosen
+// by the handler.
+type emptyRender struct {
+	status int
+	// headers are set on the response before the status is written.
+	headers map[string]string
+}
+
+var _ render.Render = emptyRender{}
+
+// Render (emptyRender) writes the status, if any, and no body.
+func (r emptyRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+	return nil
+}
+
+// WriteContentType (emptyRender) sets the headers. There is no content type
+// as there is no content. gin calls only WriteContentType, not Render, for a
+// status without a body such as 204.
+func (r emptyRender) WriteContentType(w http.ResponseWriter) {
+	for key, value := range r.headers {
+		w.Header().Set(key, value)
+	}
+}
+
+// InstanceEmpty implements Render.InstanceEmpty and DynamicRender.InstanceEmpty.
+func (r *registry) InstanceEmpty(headers map[string]string) render.Render {
+	status := r.opts.emptyStatus
+	if status == 0 {
+		status = http.StatusNoContent
+	}
+	return emptyRender{status: status, headers: headers}
+}
@@ -0,0 +1,113 @@
+package multitemplate
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// Engine parses and executes one template set with a template engine other
+// than html/template, e.g. an adapter for pongo2 or jet. Only the templates
+// registered with AddFromFilesEngine are built by an Engine; every other Add
+// method builds html/template (or, for the trusted ones, text/template)
+// templates directly. An Engine template is built by a new Engine on every
+// build, so an Engine only ever holds a single template set. Static renderers
+// keep the built Engine and execute it concurrently from every request.
+// Engine templates are always rendered as a whole: InstanceSection,
+// InstanceBlocks and InstanceOOB panic on them.
+type Engine interface {
+	// Parse parses text, the content of the file name. It is called once per
+	// file of the template, in registration order.
+	Parse(name, text string) error
+	// Execute executes the first parsed template with data and writes the
+	// output to w.
+	Execute(w io.Writer, data interface{}) error
+}
+
+// htmlEngine is the Engine backed by html/template returned by NewHTMLEngine.
+// The other builders do not go through it.
+type htmlEngine struct {
+	tmpl *template.Template
+}
+
+// NewHTMLEngine returns an Engine backed by plain html/template, mostly
+// useful as a reference implementation or as a base for engines that wrap
+// html/template. Unlike AddFromFiles, its templates are parsed without the
+// functions, delimiters and base directory of the renderer.
+func NewHTMLEngine() Engine {
+	return &htmlEngine{}
+}
+
+func (e *htmlEngine) Parse(name, text string) error {
+	var t *template.Template
+	if e.tmpl == nil {
+		e.tmpl = template.New(name)
+		t = e.tmpl
+	} else {
+		t = e.tmpl.New(name)
+	}
+	_, err := t.Parse(text)
+	return err
+}
+
+func (e *htmlEngine) Execute(w io.Writer, data interface{}) error {
+	if e.tmpl == nil {
+		return errors.New("template: no template parsed")
+	}
+	return e.tmpl.Execute(w, data)
+}
+
+// engineTemplate adapts an Engine to the templates executed by the renderers.
+type engineTemplate struct {
+	Engine
+}
+
+func (t engineTemplate) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	return fmt.Errorf("template %s: engine templates cannot execute associated templates", name)
+}
+
+// buildEngine builds the template with a new Engine.
+func (tb templateBuilder) buildEngine() (engineTemplate, error) {
+	sources, err := tb.sources()
+	if err != nil {
+		return engineTemplate{}, err
+	}
+
+	engine := tb.engine()
+	for _, source := range sources {
+		if err := engine.Parse(source.name, source.content); err != nil {
+			return engineTemplate{}, newTemplateError(err)
+		}
+	}
+	return engineTemplate{engine}, nil
+}
+
+// AddFromFilesEngine supply add template from files parsed by an Engine created
+// with newEngine, see Engine
+func (r Render) AddFromFilesEngine(name string, newEngine func() Engine, files ...string) {
+	r.add(name, newEngineBuilder(name, newEngine, files))
+}
+
+// AddFromFilesEngine supply add template from files parsed by an Engine created
+// with newEngine, see Engine
+func (r DynamicRender) AddFromFilesEngine(name string, newEngine func() Engine, files ...string) {
+	builder := newEngineBuilder(name, newEngine, files)
+	r.register(name, builder)
+	if _, err := builder.buildEngine(); err != nil {
+		panic(err)
+	}
+}
+
+func newEngineBuilder(name string, newEngine func() Engine, files []string) *templateBuilder {
+	if newEngine == nil {
+		panic("template engine cannot be nil")
+	}
+	return &templateBuilder{
+		buildType:    engineTemplateType,
+		templateName: name,
+		files:        files,
+		engine:       newEngine,
+		options:      *NewTemplateOptions(),
+	}
+}
@@ -0,0 +1,55 @@
+package multitemplate
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// replaceEngine replaces "$name" with the name entry of map data.
+type replaceEngine struct {
+	text string
+}
+
+func (e *replaceEngine) Parse(_, text string) error {
+	e.text += text
+	return nil
+}
+
+func (e *replaceEngine) Execute(w io.Writer, data interface{}) error {
+	_, err := io.WriteString(w, strings.ReplaceAll(e.text, "$name", data.(gin.H)["name"].(string)))
+	return err
+}
+
+func TestAddFromFilesEngine(t *testing.T) {
+	newEngine := func() Engine { return &replaceEngine{} }
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromFilesEngine("replace", newEngine, "tests/engine.txt")
+		r.AddFromFilesEngine("html", NewHTMLEngine, "tests/base.html", "tests/article.html")
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "replace", gin.H{"name": "engine"})
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "Hello engine\n", w.Body.String())
+
+		rec, err := renderResponse(r, "html", gin.H{"title": "Engine"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>Engine</p>\nHi, this is article template\n", rec.Body.String())
+
+		_, err = r.Build("replace")
+		assert.Error(t, err)
+		assert.PanicsWithError(t, "template html is built by an Engine and can only be rendered as a whole", func() {
+			r.InstanceSection("html", "content", nil)
+		})
+		assert.Panics(t, func() { r.InstanceBlocks("html", []string{"content"}, nil) })
+		assert.Panics(t, func() { r.AddFromFilesEngine("missing", newEngine, "tests/missing.html") })
+	}
+}
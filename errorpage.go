@@ -0,0 +1,111 @@
+package multitemplate
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorPageContext is the number of source lines shown around the error line.
+const errorPageContext = 2
+
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Template error: {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { background: #f6f6f6; padding: 1em; overflow: auto; }
+.error { color: #b00020; }
+.line { background: #ffe0e0; }
+</style>
+</head>
+<body>
+<h1>Template error</h1>
+<p>Template <code>{{.Name}}</code>{{with .Location}}, {{.TemplateName}} line {{.Line}}{{if .Column}} column {{.Column}}{{end}}{{end}}</p>
+<pre class="error">{{.Error}}</pre>
+{{- if .Source}}
+<pre>{{range .Source}}<span{{if .Current}} class="line"{{end}}>{{printf "%4d" .Number}}  {{.Text}}</span>
+{{end}}</pre>
+{{- end}}
+</body>
+</html>
+`))
+
+// sourceLine is a line of template source shown on the error page.
+type sourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// RenderErrorPage implements Render.RenderErrorPage and DynamicRender.RenderErrorPage.
+func (r *registry) RenderErrorPage(c *gin.Context, name string, err error) {
+	_ = c.Error(err)
+	if !gin.IsDebugging() {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	page := struct {
+		Name     string
+		Error    string
+		Location *TemplateError
+		Source   []sourceLine
+	}{Name: name, Error: err.Error()}
+
+	var templateErr *TemplateError
+	if errors.As(newTemplateError(err), &templateErr) {
+		page.Location = templateErr
+		page.Source = r.sourceLines(name, templateErr)
+	}
+
+	var b strings.Builder
+	if err := errorPage.Execute(&b, page); err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(http.StatusInternalServerError, htmlContentType[0], []byte(b.String()))
+	c.Abort()
+}
+
+// sourceLines returns the lines around the location of err in the source of
+// the template registered under name, or nil if the source is unknown.
+func (r *registry) sourceLines(name string, err *TemplateError) []sourceLine {
+	r.mu.RLock()
+	builder, ok := r.builders[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var content string
+	var found bool
+	if texts, textsErr := builder.texts(); textsErr == nil {
+		for _, text := range texts {
+			if text.name == err.TemplateName {
+				content, found = text.content, true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	text := strings.Split(content, "\n")
+	if err.Line < 1 || err.Line > len(text) {
+		return nil
+	}
+	first := max(err.Line-errorPageContext, 1)
+	last := min(err.Line+errorPageContext, len(text))
+	lines := make([]sourceLine, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		lines = append(lines, sourceLine{Number: n, Text: text[n-1], Current: n == err.Line})
+	}
+	return lines
+}
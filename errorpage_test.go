@@ -0,0 +1,32 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderErrorPage(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "<h1>{{ .title }}</h1>\n<p>{{ .user.name.first }}</p>\n")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		_, err := renderResponse(r, "index", gin.H{"user": "<b>"})
+		r.RenderErrorPage(c, "index", err)
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 500, w.Code)
+	assert.Contains(t, w.Body.String(), "Template <code>index</code>, index line 2 column 11")
+	assert.Contains(t, w.Body.String(), `<span class="line">   2  &lt;p&gt;{{ .user.name.first }}&lt;/p&gt;</span>`)
+	assert.Contains(t, w.Body.String(), "   1  &lt;h1&gt;")
+
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.DebugMode)
+	w = performRequest(router)
+	assert.Equal(t, 500, w.Code)
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,66 @@
+package multitemplate
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrTemplateNotFound is returned when no template is registered under a name.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrRenderTimeout is returned when a template takes longer to execute than allowed.
+	ErrRenderTimeout = errors.New("template render timed out")
+	// ErrMaxDepth is returned when templates include each other deeper than
+	// allowed, see WithMaxDepth.
+	ErrMaxDepth = errors.New("template include depth exceeds maximum")
+	// ErrInvalidBuilder is returned when a template is registered with a builder
+	// type that cannot be built, which indicates an internal inconsistency.
+	ErrInvalidBuilder = errors.New("invalid builder type")
+)
+
+// TemplateError is a template parse or execution error with the source
+// location extracted from the underlying error, e.g. to highlight it in an editor.
+type TemplateError struct {
+	// TemplateName is the name of the (associated) template the error occurred in.
+	TemplateName string
+	// Line is the 1-based line of the error.
+	Line int
+	// Column is the 1-based column of the error, or 0 if unknown.
+	Column int
+	Err    error
+}
+
+func (e *TemplateError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// templateErrorLocation matches the location prefix of text/template and
+// html/template errors, e.g. "template: index:3: " or "html/template:index:3:12: ".
+// The name ends at the first ":line: " or ":line:col: ", as it may contain
+// colons itself, e.g. "admin:users:3:12: ".
+var templateErrorLocation = regexp.MustCompile(`^(?:html/)?template: ?(.+?):(\d+)(?::(\d+))?: `)
+
+// newTemplateError wraps err in a *TemplateError if its message carries a
+// source location, and returns it unchanged otherwise.
+func newTemplateError(err error) error {
+	var templateErr *TemplateError
+	if err == nil || errors.As(err, &templateErr) {
+		return err
+	}
+
+	m := templateErrorLocation.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	templateErr = &TemplateError{TemplateName: m[1], Err: err}
+	templateErr.Line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		templateErr.Column, _ = strconv.Atoi(m[3])
+	}
+	return templateErr
+}
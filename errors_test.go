@@ -0,0 +1,40 @@
+package multitemplate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTemplateError(t *testing.T) {
+	r := NewDynamic()
+	r["broken"] = &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   "broken",
+		templateString: "line one\n{{ .name ",
+	}
+
+	_, err := r.Build("broken")
+	var templateErr *TemplateError
+	assert.ErrorAs(t, err, &templateErr)
+	assert.Equal(t, "broken", templateErr.TemplateName)
+	assert.Equal(t, 2, templateErr.Line)
+	assert.Equal(t, 0, templateErr.Column)
+
+	err = newTemplateError(errors.New(`template: index:3:12: executing "index" at <.x>: boom`))
+	assert.ErrorAs(t, err, &templateErr)
+	assert.Equal(t, "index", templateErr.TemplateName)
+	assert.Equal(t, 3, templateErr.Line)
+	assert.Equal(t, 12, templateErr.Column)
+
+	err = newTemplateError(errors.New(`template: admin:users:7:2: executing "admin:users" at <.x>: boom`))
+	assert.ErrorAs(t, err, &templateErr)
+	assert.Equal(t, "admin:users", templateErr.TemplateName)
+	assert.Equal(t, 7, templateErr.Line)
+	assert.Equal(t, 2, templateErr.Column)
+
+	plain := errors.New("no location")
+	assert.Same(t, plain, newTemplateError(plain))
+	assert.NoError(t, newTemplateError(nil))
+}
@@ -0,0 +1,92 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// statusRender writes the wrapped render with a fixed status and Content-Type.
+type statusRender struct {
+	wrapped render.Render
+	status  int
+}
+
+var _ render.Render = statusRender{}
+
+// Render (statusRender) writes the status and the wrapped render.
+func (r statusRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	w.WriteHeader(r.status)
+	return r.wrapped.Render(w)
+}
+
+// WriteContentType (statusRender) writes HTML ContentType.
+func (r statusRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = htmlContentType
+}
+
+// AddErrorPage supply add template from files registered under name and
+// rendered by InstanceError for the HTTP status
+func (r Render) AddErrorPage(status int, name string, files ...string) *template.Template {
+	tmpl := r.AddFromFiles(name, files...)
+	r.registry().setErrorPage(status, name)
+	return tmpl
+}
+
+// AddErrorPage supply add template from files registered under name and
+// rendered by InstanceError for the HTTP status
+func (r DynamicRender) AddErrorPage(status int, name string, files ...string) *template.Template {
+	tmpl := r.AddFromFiles(name, files...)
+	r.registry().setErrorPage(status, name)
+	return tmpl
+}
+
+func (r *registry) setErrorPage(status int, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.errorPages == nil {
+		r.errorPages = make(map[int]string)
+	}
+	r.errorPages[status] = name
+}
+
+// InstanceError implements Render.InstanceError and DynamicRender.InstanceError.
+func (r *registry) InstanceError(status int, data interface{}) render.Render {
+	r.mu.RLock()
+	name, ok := r.errorPages[status]
+	r.mu.RUnlock()
+	if !ok {
+		return emptyRender{status: status}
+	}
+	name = r.resolve(nil, name)
+	rr := r.instance(nil, name, data, instanceOptions{})
+	if cacheControl := r.cachePolicy(name); cacheControl != "" {
+		rr = headerRender{wrapped: rr, cacheControl: cacheControl}
+	}
+	return statusRender{wrapped: rr, status: status}
+}
+
+// PanicInfo is the data of the template rendered by WithPanicHandler. In
+// release mode Template is empty and Error is the status text, so no
+// internals leak.
+type PanicInfo struct {
+	Status   int
+	Template string
+	Error    string
+}
+
+// panicPage renders the template of WithPanicHandler for the panic p raised
+// while rendering the template name.
+func (r *registry) panicPage(name string, p interface{}) render.Render {
+	info := PanicInfo{Status: http.StatusInternalServerError, Error: http.StatusText(http.StatusInternalServerError)}
+	if gin.IsDebugging() {
+		info.Template = name
+		info.Error = fmt.Sprint(p)
+	}
+	rr := r.instance(nil, r.resolve(nil, r.opts.panicTemplate), info, instanceOptions{uncached: true})
+	return statusRender{wrapped: rr, status: http.StatusInternalServerError}
+}
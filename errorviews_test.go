@@ -0,0 +1,56 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceError(t *testing.T) {
+	for _, r := range []testRenderer{New(WithContentType("application/xhtml+xml")), NewDynamic()} {
+		r.AddErrorPage(404, "errors/404", "tests/errors/404.html")
+		r.AddErrorPage(500, "errors/500", "tests/errors/500.html")
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.Render(200, r.InstanceError(404, gin.H{"path": "/missing"}))
+		})
+		w := performRequest(router)
+		assert.Equal(t, 404, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "<h1>Not found: /missing</h1>\n", w.Body.String())
+
+		router = gin.New()
+		router.GET("/", func(c *gin.Context) {
+			c.Render(200, r.InstanceError(400, nil))
+		})
+		w = performRequest(router)
+		assert.Equal(t, 400, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.True(t, r.Exists("errors/500"))
+	}
+}
+
+func TestWithPanicHandler(t *testing.T) {
+	r := NewDynamic(WithPanicHandler("500"))
+	r.AddFromString("500", `<h1>Error {{ .Status }}</h1>{{ with .Template }}<p>{{ . }}: {{ $.Error }}</p>{{ end }}`)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "missing", nil)
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "<h1>Error 500</h1><p>missing: template not found: missing</p>", w.Body.String())
+
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.DebugMode)
+	w = performRequest(router)
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, "<h1>Error 500</h1>", w.Body.String())
+}
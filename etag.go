@@ -0,0 +1,74 @@
+package multitemplate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// etagRender sets the ETag header before writing the wrapped render. With
+// notModified it writes a 304 status and no body instead.
+type etagRender struct {
+	wrapped     render.Render
+	etag        string
+	notModified bool
+}
+
+var _ render.Render = etagRender{}
+
+// Render (etagRender) sets the ETag and writes the wrapped render or a 304.
+func (r etagRender) Render(w http.ResponseWriter) error {
+	w.Header().Set("ETag", r.etag)
+	if r.notModified {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	return r.wrapped.Render(w)
+}
+
+// WriteContentType (etagRender) writes the ContentType of the wrapped render.
+func (r etagRender) WriteContentType(w http.ResponseWriter) {
+	if !r.notModified {
+		r.wrapped.WriteContentType(w)
+	}
+}
+
+// InstanceETag implements Render.InstanceETag and DynamicRender.InstanceETag.
+func (r *registry) InstanceETag(c *gin.Context, name string, data interface{}, etag string) render.Render {
+	if !validETag(etag) {
+		panic(fmt.Sprintf("invalid etag %q", etag))
+	}
+	weak := `W/"` + etag + `"`
+	if etagMatches(c.GetHeader("If-None-Match"), weak) {
+		return etagRender{etag: weak, notModified: true}
+	}
+	return etagRender{wrapped: r.InstanceCtx(c, name, data), etag: weak}
+}
+
+// validETag reports whether etag only has the characters allowed between
+// the quotes of an entity tag by RFC 9110, which excludes quotes, spaces and
+// control characters.
+func validETag(etag string) bool {
+	for i := 0; i < len(etag); i++ {
+		if c := etag[i]; c != 0x21 && (c < 0x23 || c == 0x7f) {
+			return false
+		}
+	}
+	return true
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag
+// using the weak comparison, which ignores the W/ prefix of both tags.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package multitemplate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceETag(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceETag(c, "index", gin.H{"name": "index"}, "v1"))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `W/"v1"`, w.Header().Get("ETag"))
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+
+	for header, code := range map[string]int{
+		`W/"v1"`:           http.StatusNotModified,
+		`"v0", "v1"`:       http.StatusNotModified,
+		`*`:                http.StatusNotModified,
+		`W/"v0"`:           http.StatusOK,
+		`W/"v1-different"`: http.StatusOK,
+	} {
+		req, _ := http.NewRequestWithContext(context.Background(), "GET", "/", nil)
+		req.Header.Set("If-None-Match", header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, header)
+		assert.Equal(t, `W/"v1"`, w.Header().Get("ETag"))
+		if code == http.StatusNotModified {
+			assert.Empty(t, w.Body.String())
+		}
+	}
+}
+
+func TestInstanceETagInvalid(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "Welcome")
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	for _, etag := range []string{`v"1`, "v 1", "v\n1"} {
+		assert.Panics(t, func() { r.InstanceETag(c, "index", nil, etag) }, etag)
+	}
+	assert.NotPanics(t, func() { r.InstanceETag(c, "index", nil, "v1-é/2") })
+}
@@ -0,0 +1,150 @@
+package multitemplate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ExportJob is a page written by ExportStatic.
+type ExportJob struct {
+	// Name is the name of the template to render.
+	Name string
+	Data interface{}
+	// Path is the slash separated path of the output file, relative to the
+	// export directory, e.g. "docs/index.html".
+	Path string
+	// Gzip also writes a gzip compressed copy of the page next to it, with
+	// a ".gz" suffix, for servers serving precompressed files.
+	Gzip bool
+}
+
+// RenderToWriter implements Render.RenderToWriter and DynamicRender.RenderToWriter.
+func (r *registry) RenderToWriter(w io.Writer, name string, data interface{}) error {
+	out, err := r.renderBytes(name, data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// ExportStatic implements Render.ExportStatic and DynamicRender.ExportStatic.
+func (r *registry) ExportStatic(dir string, jobs []ExportJob) error {
+	for _, job := range jobs {
+		if err := r.export(dir, job); err != nil {
+			return fmt.Errorf("export %s: %w", job.Path, err)
+		}
+	}
+	return nil
+}
+
+func (r *registry) export(dir string, job ExportJob) error {
+	out, err := r.renderBytes(job.Name, job.Data)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(job.Path))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return err
+	}
+	if !job.Gzip {
+		return nil
+	}
+
+	f, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(out); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SourceBundle is the source of a registered template, see ExportSources.
+type SourceBundle struct {
+	// Type is the kind of registration, e.g. "files", "glob" or "string".
+	Type string `json:"type"`
+	// Trusted is set for templates parsed with text/template.
+	Trusted bool `json:"trusted,omitempty"`
+	// LeftDelimiter and RightDelimiter are set if they are not the default ones.
+	LeftDelimiter  string `json:"leftDelimiter,omitempty"`
+	RightDelimiter string `json:"rightDelimiter,omitempty"`
+	// Strings are the bodies of string templates, in parse order.
+	Strings []string `json:"strings,omitempty"`
+	// Archive is the zip archive of zip templates, see AddFromZip.
+	Archive string `json:"archive,omitempty"`
+	// Files are the resolved paths of the files the template is parsed from,
+	// in parse order, relative to the fs.FS or archive if any.
+	Files []string `json:"files,omitempty"`
+	// Contents are the current contents of Files, keyed by path.
+	Contents map[string]string `json:"contents,omitempty"`
+}
+
+// ExportSources implements Render.ExportSources and DynamicRender.ExportSources.
+func (r *registry) ExportSources() (map[string]SourceBundle, error) {
+	bundles := make(map[string]SourceBundle)
+	for _, name := range r.Names() {
+		r.mu.RLock()
+		builder, ok := r.builders[name]
+		var raw templateBuilder
+		if ok {
+			raw = *builder
+		}
+		r.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		bundle, err := raw.exportSource()
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		bundles[name] = bundle
+	}
+	return bundles, nil
+}
+
+// exportSource returns the source of the builder, see ExportSources.
+func (tb templateBuilder) exportSource() (SourceBundle, error) {
+	bundle := SourceBundle{Type: tb.buildType.String(), Trusted: tb.text, Archive: tb.zipPath}
+	if defaults := NewTemplateOptions(); tb.options != *defaults && tb.options != (TemplateOptions{}) {
+		bundle.LeftDelimiter = tb.options.LeftDelimiter
+		bundle.RightDelimiter = tb.options.RightDelimiter
+	}
+
+	switch tb.buildType {
+	case stringTemplateType:
+		bundle.Strings = []string{tb.templateString}
+		return bundle, nil
+	case stringFuncTemplateType:
+		bundle.Strings = append([]string(nil), tb.templateStrings...)
+		return bundle, nil
+	}
+
+	// without settings the sources are read as written and without common files
+	tb.settings = nil
+	sources, err := tb.sources()
+	if err != nil || len(sources) == 0 {
+		return bundle, err
+	}
+	bundle.Contents = make(map[string]string, len(sources))
+	for _, source := range sources {
+		bundle.Files = append(bundle.Files, source.path)
+		bundle.Contents[source.path] = source.content
+	}
+	return bundle, nil
+}
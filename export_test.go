@@ -0,0 +1,101 @@
+package multitemplate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderToWriter(t *testing.T) {
+	r := createFromStringDynamic().(testRenderer)
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.RenderToWriter(&buf, "index", gin.H{"name": "writer"}))
+	assert.Equal(t, "Welcome to writer template", buf.String())
+	assert.ErrorIs(t, r.RenderToWriter(&buf, "missing", nil), ErrTemplateNotFound)
+}
+
+func TestExportStatic(t *testing.T) {
+	r := createFromStringDynamic().(testRenderer)
+	dir := t.TempDir()
+
+	err := r.ExportStatic(dir, []ExportJob{
+		{Name: "index", Data: gin.H{"name": "home"}, Path: "index.html"},
+		{Name: "index", Data: gin.H{"name": "docs"}, Path: "docs/index.html", Gzip: true},
+	})
+	assert.NoError(t, err)
+
+	b, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to home template", string(b))
+	assert.NoFileExists(t, filepath.Join(dir, "index.html.gz"))
+
+	f, err := os.Open(filepath.Join(dir, "docs", "index.html.gz"))
+	assert.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	b, err = io.ReadAll(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to docs template", string(b))
+
+	err = r.ExportStatic(dir, []ExportJob{{Name: "missing", Path: "missing.html"}})
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestExportSources(t *testing.T) {
+	welcome, err := os.ReadFile("tests/welcome.html")
+	assert.NoError(t, err)
+	fsys := fstest.MapFS{
+		"views/a.html": {Data: []byte(`a {{ template "b" . }}`)},
+		"views/b.html": {Data: []byte(`{{ define "b" }}b{{ end }}`)},
+	}
+
+	for _, r := range []testRenderer{New(WithFrontMatter()), NewDynamic(WithFrontMatter())} {
+		r.SetCommonFiles("tests/welcome.html")
+		r.AddFromFiles("files", "tests/welcome.html")
+		r.AddFromFS("fs", fsys, "views/*.html")
+		r.AddFromString("string", "---\ntitle: Home\n---\nWelcome {{ .name }}")
+		r.AddFromStringDelims("delims", "[[", "]]", "Welcome [[ .name ]]")
+		r.Add("template", template.Must(template.New("template").Parse("Welcome")))
+
+		bundles, err := r.ExportSources()
+		assert.NoError(t, err)
+		assert.Len(t, bundles, 5)
+		assert.Equal(t, SourceBundle{
+			Type:     "files",
+			Files:    []string{"tests/welcome.html"},
+			Contents: map[string]string{"tests/welcome.html": string(welcome)},
+		}, bundles["files"])
+		assert.Equal(t, SourceBundle{
+			Type:  "fs",
+			Files: []string{"views/a.html", "views/b.html"},
+			Contents: map[string]string{
+				"views/a.html": `a {{ template "b" . }}`,
+				"views/b.html": `{{ define "b" }}b{{ end }}`,
+			},
+		}, bundles["fs"])
+		assert.Equal(t, SourceBundle{Type: "string", Strings: []string{"---\ntitle: Home\n---\nWelcome {{ .name }}"}},
+			bundles["string"])
+		assert.Equal(t, "[[", bundles["delims"].LeftDelimiter)
+		assert.Equal(t, "]]", bundles["delims"].RightDelimiter)
+		assert.Equal(t, SourceBundle{Type: "template"}, bundles["template"])
+	}
+
+	r := NewDynamic()
+	r["missing"] = &templateBuilder{
+		buildType:    filesTemplateType,
+		templateName: "missing",
+		files:        []string{"tests/missing.html"},
+	}
+	_, err = r.ExportSources()
+	assert.ErrorContains(t, err, "template missing: ")
+}
@@ -0,0 +1,68 @@
+package multitemplate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelimiter opens and closes the front matter of a template source.
+const frontMatterDelimiter = "---"
+
+// splitFrontMatter splits the YAML front matter delimited by "---" lines off
+// the top of src and returns it decoded together with the remaining body.
+// Sources without front matter return nil metadata and src unchanged.
+func splitFrontMatter(src string) (map[string]interface{}, string, error) {
+	first, rest, ok := strings.Cut(src, "\n")
+	if !ok || strings.TrimRight(first, "\r") != frontMatterDelimiter {
+		return nil, src, nil
+	}
+
+	var front strings.Builder
+	for rest != "" {
+		var line string
+		line, rest, _ = strings.Cut(rest, "\n")
+		if strings.TrimRight(line, "\r") == frontMatterDelimiter {
+			meta := make(map[string]interface{})
+			if err := yaml.Unmarshal([]byte(front.String()), &meta); err != nil {
+				return nil, "", fmt.Errorf("front matter: %w", err)
+			}
+			return meta, rest, nil
+		}
+		front.WriteString(line)
+		front.WriteByte('\n')
+	}
+	return nil, "", errors.New("front matter: missing closing " + frontMatterDelimiter)
+}
+
+// frontMatter returns the front matter of the root source of the builder,
+// or nil if it has none.
+func (tb templateBuilder) frontMatter() (map[string]interface{}, error) {
+	texts, err := tb.texts()
+	if err != nil {
+		return nil, err
+	}
+	for _, text := range texts {
+		if !text.common {
+			return text.meta, nil
+		}
+	}
+	return nil, nil
+}
+
+// Meta implements Render.Meta and DynamicRender.Meta.
+func (r *registry) Meta(name string) (map[string]interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	builder, ok := r.builders[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	if r.dynamic && !builder.pinned {
+		return builder.frontMatter()
+	}
+	return builder.meta, nil
+}
@@ -0,0 +1,55 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	meta, body, err := splitFrontMatter("---\r\ntitle: Home\r\n---\r\n<p>body</p>")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "Home"}, meta)
+	assert.Equal(t, "<p>body</p>", body)
+
+	meta, body, err = splitFrontMatter("<p>---</p>\n")
+	assert.NoError(t, err)
+	assert.Nil(t, meta)
+	assert.Equal(t, "<p>---</p>\n", body)
+
+	_, _, err = splitFrontMatter("---\ntitle: Home\n")
+	assert.EqualError(t, err, "front matter: missing closing ---")
+	_, _, err = splitFrontMatter("---\ntitle: [\n---\n")
+	assert.Error(t, err)
+}
+
+func TestWithFrontMatter(t *testing.T) {
+	for _, r := range []testRenderer{New(WithFrontMatter()), NewDynamic(WithFrontMatter())} {
+		r.AddFromFiles("page", "tests/frontmatter/page.html")
+		r.AddFromString("plain", "<p>plain</p>")
+
+		meta, err := r.Meta("page")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"title": "Dashboard",
+			"roles": []interface{}{"admin", "editor"},
+			"cache": 60,
+		}, meta)
+
+		w, err := renderResponse(r, "page", gin.H{"title": meta["title"]})
+		assert.NoError(t, err)
+		assert.Equal(t, "<h1>Dashboard</h1>\n", w.Body.String())
+
+		meta, err = r.Meta("plain")
+		assert.NoError(t, err)
+		assert.Nil(t, meta)
+
+		_, err = r.Meta("missing")
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	}
+
+	assert.Panics(t, func() {
+		New(WithFrontMatter()).AddFromString("broken", "---\ntitle: Home\n")
+	})
+}
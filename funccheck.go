@@ -0,0 +1,98 @@
+package multitemplate
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// textFuncs parses the texts of the builder without checking functions and
+// returns the sorted names of the functions they call, including builtin ones.
+func (tb templateBuilder) textFuncs() ([]string, error) {
+	texts, err := tb.texts()
+	if err != nil {
+		return nil, err
+	}
+
+	trees := make(map[string]*parse.Tree)
+	for i, text := range texts {
+		tree := parse.New(text.name)
+		tree.Mode = parse.SkipFuncCheck
+		treeSet := make(map[string]*parse.Tree)
+		if _, err := tree.Parse(text.content, tb.options.LeftDelimiter, tb.options.RightDelimiter, treeSet); err != nil {
+			return nil, err
+		}
+		for name, t := range treeSet {
+			trees[fmt.Sprintf("%d/%s", i, name)] = t
+		}
+	}
+	return calledFuncs(trees), nil
+}
+
+// checkParseError returns an error naming every function the texts of the
+// builder call that is undefined, with WithFuncCheck, or not allowed, with
+// WithAllowedFuncs. It is called when the texts failed to parse, as the
+// parser stops at the first undefined function, and returns nil if they have
+// none or do not parse even without checking functions.
+func (tb templateBuilder) checkParseError() error {
+	if tb.settings == nil || !tb.settings.checkFuncs && tb.settings.allowedFuncs == nil {
+		return nil
+	}
+	called, err := tb.textFuncs()
+	if err != nil {
+		return nil
+	}
+	if tb.settings.checkFuncs {
+		if err := tb.checkDefinedFuncs(called); err != nil {
+			return err
+		}
+	}
+	return tb.checkAllowedFuncs(called)
+}
+
+// checkParsed returns an error naming every function the parsed template
+// tmpl calls that is not allowed, see WithAllowedFuncs. Templates that are
+// not parsed from the texts of the builder are not checked.
+func (tb templateBuilder) checkParsed(tmpl executor) error {
+	switch tb.buildType {
+	case templateType, lazyTemplateType, treeTemplateType:
+		return nil
+	}
+	return tb.checkAllowedFuncs(calledFuncs(templateTrees(tmpl)))
+}
+
+// checkDefinedFuncs returns an error naming every function of called that is
+// neither builtin nor available to the builder.
+func (tb templateBuilder) checkDefinedFuncs(called []string) error {
+	funcs := tb.funcs()
+	var missing []string
+	for _, fn := range called {
+		if _, ok := funcs[fn]; !ok && !builtinFuncs[fn] {
+			missing = append(missing, fn)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("template %s calls undefined functions: %s", tb.templateName, strings.Join(missing, ", "))
+}
+
+// checkAllowedFuncs returns an error naming every function of called that is
+// neither builtin nor allowed, see WithAllowedFuncs. The builtin "call"
+// calls any function value of the data, so it must be allowed explicitly.
+func (tb templateBuilder) checkAllowedFuncs(called []string) error {
+	if tb.settings == nil || tb.settings.allowedFuncs == nil {
+		return nil
+	}
+
+	var denied []string
+	for _, fn := range called {
+		if !tb.settings.allowedFuncs[fn] && (fn == "call" || !builtinFuncs[fn]) {
+			denied = append(denied, fn)
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	return fmt.Errorf("template %s calls functions that are not allowed: %s", tb.templateName, strings.Join(denied, ", "))
+}
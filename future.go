@@ -0,0 +1,66 @@
+package multitemplate
+
+import "fmt"
+
+// Future is a value computed in the background, e.g. one of several
+// independent data fetches of a page. Pass it in the template data and read
+// it with the await template function, which blocks until it is computed:
+//
+//	data := gin.H{"User": NewFuture(loadUser), "Feed": NewFuture(loadFeed)}
+//	{{ with await .User }}{{ .Name }}{{ end }}
+//
+// Both fetches run while the template renders the sections before them.
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// NewFuture starts computing fn in a new goroutine and returns its Future.
+// A panic in fn is returned as the error of the Future.
+func NewFuture[T any](fn func() (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		defer func() {
+			if p := recover(); p != nil {
+				f.err = fmt.Errorf("future panicked: %v", p)
+			}
+		}()
+		f.value, f.err = fn()
+	}()
+	return f
+}
+
+// Get blocks until the value is computed and returns it.
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// awaitValue returns the computed value of the future as an interface{}.
+func (f *Future[T]) awaitValue() (interface{}, error) {
+	return f.Get()
+}
+
+// awaiter is implemented by every Future.
+type awaiter interface {
+	awaitValue() (interface{}, error)
+}
+
+// predefinedFuncs are available to every template parsed by the renderers,
+// like the builtin functions, unless the function map replaces them.
+var predefinedFuncs = map[string]interface{}{
+	"await":    await,
+	"listRows": listRows,
+}
+
+// await is the await template function: it returns the value of a Future,
+// blocking until it is computed, and any other value unchanged. The error of
+// the Future fails the render.
+func await(v interface{}) (interface{}, error) {
+	if f, ok := v.(awaiter); ok {
+		return f.awaitValue()
+	}
+	return v, nil
+}
@@ -0,0 +1,56 @@
+package multitemplate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuture(t *testing.T) {
+	release := make(chan struct{})
+	user := NewFuture(func() (string, error) {
+		<-release
+		return "gin", nil
+	})
+	failed := NewFuture(func() (int, error) {
+		return 0, errors.New("feed unavailable")
+	})
+
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromString("index", `Hello {{ await .user }}{{ await .plain }}`)
+		r.AddTrustedFromString("text", `{{ await .user }}`)
+		r.AddFromString("failed", `{{ await .feed }}`)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			select {
+			case <-release:
+			default:
+				close(release)
+			}
+		}()
+		w, err := renderResponse(r, "index", gin.H{"user": user, "plain": "!"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello gin!", w.Body.String())
+
+		w, err = renderResponse(r, "text", gin.H{"user": user})
+		assert.NoError(t, err)
+		assert.Equal(t, "gin", w.Body.String())
+
+		_, err = renderResponse(r, "failed", gin.H{"feed": failed})
+		assert.ErrorContains(t, err, "feed unavailable")
+	}
+}
+
+func TestFuturePanic(t *testing.T) {
+	f := NewFuture(func() (string, error) {
+		panic("fetch failed")
+	})
+
+	value, err := f.Get()
+	assert.Empty(t, value)
+	assert.EqualError(t, err, "future panicked: fetch failed")
+}
@@ -0,0 +1,91 @@
+package multitemplate
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// headerRender sets the response headers configured on the renderer before
+// writing the response of the wrapped render.
+type headerRender struct {
+	wrapped render.Render
+	// cacheControl is the Cache-Control header, see SetCachePolicy.
+	cacheControl string
+	// contentType replaces the default Content-Type, see WithContentType.
+	contentType string
+}
+
+// Render (headerRender) sets the headers and writes the wrapped render.
+func (r headerRender) Render(w http.ResponseWriter) error {
+	r.setHeaders(w)
+	return r.wrapped.Render(w)
+}
+
+// WriteContentType (headerRender) sets the headers and writes the ContentType.
+func (r headerRender) WriteContentType(w http.ResponseWriter) {
+	r.setHeaders(w)
+	r.wrapped.WriteContentType(w)
+}
+
+func (r headerRender) setHeaders(w http.ResponseWriter) {
+	header := w.Header()
+	if r.cacheControl != "" {
+		header.Set("Cache-Control", r.cacheControl)
+	}
+	if r.contentType != "" && len(header["Content-Type"]) == 0 {
+		header["Content-Type"] = []string{r.contentType}
+	}
+}
+
+// headers wraps rr, the render of the template name, to set the response
+// headers the renderer is configured with.
+func (r *registry) headers(name string, rr render.Render) render.Render {
+	cacheControl := r.cachePolicy(name)
+	if cacheControl == "" && r.opts.contentType == "" {
+		return rr
+	}
+	contentType := r.opts.contentType
+	inner := rr
+	if wrapped, ok := inner.(writerRender); ok {
+		inner = wrapped.wrapped
+	}
+	if buffered, ok := inner.(bufferedRender); ok && buffered.contentType != "" {
+		// the Content-Type of the template takes precedence
+		contentType = ""
+	}
+	return headerRender{wrapped: rr, cacheControl: cacheControl, contentType: contentType}
+}
+
+// SetCachePolicy implements Render.SetCachePolicy and DynamicRender.SetCachePolicy.
+func (r *registry) SetCachePolicy(name, policy string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cachePolicies == nil {
+		r.cachePolicies = make(map[string]string)
+	}
+	if policy == "" {
+		delete(r.cachePolicies, name)
+		return
+	}
+	r.cachePolicies[name] = policy
+}
+
+// cachePolicy returns the Cache-Control header of the template name, or an
+// empty string to send none.
+func (r *registry) cachePolicy(name string) string {
+	r.mu.RLock()
+	policy, ok := r.cachePolicies[name]
+	r.mu.RUnlock()
+	switch {
+	case ok:
+		return policy
+	case r.opts.cachePolicy != "":
+		return r.opts.cachePolicy
+	case r.opts.noStore:
+		return "no-store"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,93 @@
+package multitemplate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoStore(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer testRenderer
+		expected string
+	}{
+		{"static", New(), ""},
+		{"dynamic", NewDynamic(), "no-store"},
+		{"static enabled", New(WithNoStore(true)), "no-store"},
+		{"dynamic disabled", NewDynamic(WithNoStore(false)), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.renderer.AddFromString("index", "Welcome")
+
+			router := gin.New()
+			router.HTMLRender = tt.renderer
+			router.GET("/", func(c *gin.Context) {
+				c.HTML(200, "index", nil)
+			})
+
+			w := performRequest(router)
+			assert.Equal(t, "Welcome", w.Body.String())
+			assert.Equal(t, tt.expected, w.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func TestSetCachePolicy(t *testing.T) {
+	for _, tt := range []struct {
+		renderer testRenderer
+		expected string
+	}{
+		{New(), ""},
+		{NewDynamic(), "no-store"},
+		{New(WithCachePolicy("private, max-age=60")), "private, max-age=60"},
+		{NewDynamic(WithCachePolicy("private, max-age=60")), "private, max-age=60"},
+	} {
+		r := tt.renderer
+		r.AddFromString("index", "Welcome")
+		r.AddFromString("about", "About")
+		r.SetCachePolicy("about", "public, max-age=3600")
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, c.Query("name"), nil)
+		})
+		get := func(path string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequestWithContext(context.Background(), "GET", path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		w := get("/?name=index")
+		assert.Equal(t, tt.expected, w.Header().Get("Cache-Control"))
+		w = get("/?name=about")
+		assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+
+		r.SetCachePolicy("about", "")
+		w = get("/?name=about")
+		assert.Equal(t, tt.expected, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestWithContentType(t *testing.T) {
+	for _, r := range []testRenderer{New(WithContentType("text/html")), NewDynamic(WithContentType("text/html"))} {
+		r.AddFromString("index", "Welcome")
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "index", nil)
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, "Welcome", w.Body.String())
+		assert.Equal(t, "text/html", w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,43 @@
+package multitemplate
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// InstanceHydrate implements Render.InstanceHydrate and DynamicRender.InstanceHydrate.
+func (r *registry) InstanceHydrate(name string, data interface{}) render.Render {
+	name = r.resolve(nil, name)
+	return r.headers(name, r.instance(nil, name, data, instanceOptions{
+		uncached: true,
+		postProcess: func(out []byte) ([]byte, error) {
+			return appendHydrationData(out, data)
+		},
+	}))
+}
+
+// appendHydrationData inserts the hydration script of data into out.
+func appendHydrationData(out []byte, data interface{}) ([]byte, error) {
+	// json.Marshal escapes <, > and & as well as U+2028 and U+2029, so
+	// the encoded data is safe inside a script element.
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var script bytes.Buffer
+	script.WriteString(`<script id="__data__" type="application/json">`)
+	script.Write(encoded)
+	script.WriteString(`</script>`)
+
+	i := bytes.LastIndex(out, []byte("</body>"))
+	if i < 0 {
+		return append(out, script.Bytes()...), nil
+	}
+	hydrated := make([]byte, 0, len(out)+script.Len())
+	hydrated = append(hydrated, out[:i]...)
+	hydrated = append(hydrated, script.Bytes()...)
+	return append(hydrated, out[i:]...), nil
+}
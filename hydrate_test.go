@@ -0,0 +1,37 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceHydrate(t *testing.T) {
+	r := New()
+	r.AddFromString("page", "<html><body><p>{{ .name }}</p></body></html>")
+	r.AddFromString("fragment", "<p>{{ .name }}</p>")
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceHydrate("page", gin.H{"name": "</script><b>"}))
+	})
+	w := performRequest(router)
+	assert.Equal(t, `<html><body><p>&lt;/script&gt;&lt;b&gt;</p>`+
+		`<script id="__data__" type="application/json">{"name":"\u003c/script\u003e\u003cb\u003e"}</script>`+
+		`</body></html>`, w.Body.String())
+
+	router = gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceHydrate("fragment", gin.H{"name": "gin"}))
+	})
+	w = performRequest(router)
+	assert.Equal(t, `<p>gin</p><script id="__data__" type="application/json">{"name":"gin"}</script>`, w.Body.String())
+
+	router = gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceHydrate("fragment", gin.H{"name": func() {}}))
+	})
+	w = performRequest(router)
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,135 @@
+package multitemplate
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+)
+
+// TemplateInfo describes a registered template and the sources it is built from.
+type TemplateInfo struct {
+	Name string `json:"name"`
+	// Type is the kind of registration, e.g. "files", "glob" or "string".
+	Type string `json:"type"`
+	// Files are the files or fs.FS patterns the template is parsed from.
+	Files []string `json:"files,omitempty"`
+	// Glob is the pattern of templates added with AddFromGlob.
+	Glob string `json:"glob,omitempty"`
+	// Trusted is set for templates parsed with text/template.
+	Trusted bool `json:"trusted,omitempty"`
+	// Funcs are the sorted names of the functions the template can call,
+	// see Funcs.
+	Funcs []string `json:"funcs,omitempty"`
+	// Defined are the names of all templates defined in the set.
+	Defined []string `json:"defined"`
+	// Error is set if the template failed to build.
+	Error string `json:"error,omitempty"`
+}
+
+// Names implements Render.Names and DynamicRender.Names.
+func (r *registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return sortedNames(r.builders)
+}
+
+// MustNames implements Render.MustNames and DynamicRender.MustNames.
+func (r *registry) MustNames(expected ...string) {
+	registered := make(map[string]bool)
+	for _, name := range r.Names() {
+		registered[name] = true
+	}
+
+	var missing []string
+	for _, name := range expected {
+		if !registered[name] {
+			missing = append(missing, name)
+		}
+		delete(registered, name)
+	}
+	sort.Strings(missing)
+	extra := sortedNames(registered)
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "unexpected "+strings.Join(extra, ", "))
+	}
+	if len(problems) > 0 {
+		panic(fmt.Sprintf("registered templates do not match: %s", strings.Join(problems, "; ")))
+	}
+}
+
+// AllDefinedTemplates implements Render.AllDefinedTemplates and DynamicRender.AllDefinedTemplates.
+func (r *registry) AllDefinedTemplates() map[string][]string {
+	defined := make(map[string][]string)
+	for _, name := range r.Names() {
+		if names, err := r.DefinedTemplates(name); err == nil {
+			defined[name] = names
+		}
+	}
+	return defined
+}
+
+// ForEach implements Render.ForEach and DynamicRender.ForEach.
+func (r *registry) ForEach(fn func(name string, tmpl *template.Template) bool) {
+	for _, name := range r.Names() {
+		tmpl, err := r.Build(name)
+		if errors.Is(err, ErrTemplateNotFound) {
+			continue
+		}
+		if !fn(name, tmpl) {
+			return
+		}
+	}
+}
+
+// sortedNames returns the keys of m, sorted.
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Funcs implements Render.Funcs and DynamicRender.Funcs.
+func (r *registry) Funcs(name string) template.FuncMap {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	builder, ok := r.builders[name]
+	if !ok {
+		return nil
+	}
+	return builder.funcs()
+}
+
+// funcs returns the functions the builder parses its template with.
+func (tb templateBuilder) funcs() template.FuncMap {
+	funcMap := template.FuncMap{}
+	if tb.settings != nil {
+		for name, fn := range tb.settings.funcs() {
+			funcMap[name] = fn
+		}
+	}
+	for name, fn := range tb.funcMap {
+		funcMap[name] = fn
+	}
+	if tb.settings != nil {
+		for name, fn := range funcMap {
+			funcMap[name] = tb.settings.lenient(name, fn)
+		}
+	}
+	return funcMap
+}
+
+// DumpJSON implements Render.DumpJSON and DynamicRender.DumpJSON.
+func (r *registry) DumpJSON() ([]byte, error) {
+	return r.Snapshot().DumpJSON()
+}
@@ -0,0 +1,192 @@
+package multitemplate
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpJSON(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromFiles("index", "tests/base.html", "tests/article.html")
+	r.AddFromGlob("login", "tests/global/*")
+	r.AddFromString("string", "Welcome to {{ .name }} template")
+
+	b, err := r.DumpJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name":"index","type":"files","files":["tests/base.html","tests/article.html"],
+		 "defined":["article.html","base.html"]},
+		{"name":"login","type":"glob","glob":"tests/global/*","defined":["base.html","login.html"]},
+		{"name":"string","type":"string","defined":["string"]}
+	]`, string(b))
+
+	b, err = New().DumpJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(b))
+}
+
+func TestFuncs(t *testing.T) {
+	r := New()
+	r.SetDebugFuncs(template.FuncMap{"dump": func(interface{}) string { return "" }})
+	r.AddFromStringsFuncs("index", template.FuncMap{"upper": strings.ToUpper}, "{{ upper .name }}")
+	r.AddFromString("plain", "plain")
+
+	funcs := r.Funcs("index")
+	assert.Len(t, funcs, 2)
+	assert.Contains(t, funcs, "upper")
+	assert.Contains(t, funcs, "dump")
+	assert.Len(t, r.Funcs("plain"), 1)
+	assert.Nil(t, r.Funcs("missing"))
+
+	b, err := r.DumpJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"funcs":["dump","upper"]`)
+}
+
+func TestNames(t *testing.T) {
+	r := New()
+	for _, name := range []string{"c", "a", "b"} {
+		r.AddFromString(name, name)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, r.Names())
+	assert.Empty(t, NewDynamic().Names())
+}
+
+func TestAllDefinedTemplates(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromFiles("index", "tests/base.html", "tests/article.html")
+	r.AddFromString("page", `{{block "content" .}}default{{end}}{{define "sidebar"}}{{end}}`)
+	r["broken"] = &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   "broken",
+		templateString: "{{ .name ",
+	}
+
+	assert.Equal(t, map[string][]string{
+		"index": {"article.html", "base.html"},
+		"page":  {"content", "page", "sidebar"},
+	}, r.AllDefinedTemplates())
+}
+
+func TestMustNames(t *testing.T) {
+	r := New()
+	r.AddFromString("home", "home")
+	r.AddFromString("about", "about")
+
+	assert.NotPanics(t, func() { r.MustNames("about", "home") })
+	assert.PanicsWithValue(t, "registered templates do not match: missing contact, hom; unexpected home", func() {
+		r.MustNames("about", "hom", "contact")
+	})
+	assert.PanicsWithValue(t, "registered templates do not match: unexpected about", func() {
+		r.MustNames("home")
+	})
+}
+
+func TestForEach(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromString("b", "b")
+	r.AddFromString("a", "a")
+	r.AddTrustedFromString("c", "c")
+	r.AddFromString("d", "d")
+
+	var names []string
+	var nilTemplates int
+	r.ForEach(func(name string, tmpl *template.Template) bool {
+		names = append(names, name)
+		if tmpl == nil {
+			nilTemplates++
+		} else {
+			assert.Equal(t, name, tmpl.Name())
+		}
+		return name != "c"
+	})
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, 1, nilTemplates)
+}
+
+func TestUsedFuncs(t *testing.T) {
+	funcMap := template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"title": strings.ToTitle,
+		"trim":  strings.TrimSpace,
+	}
+	r := New()
+	r.AddFromStringsFuncs("index", funcMap,
+		`{{ upper .name }}{{ if eq (len .items) 0 }}{{ lower "X" }}{{ end }}{{ template "sub" (trim .name) }}`,
+		`{{ define "sub" }}<a href="{{ . }}">{{ printf "%s" . | title }}</a>{{ end }}`)
+
+	used, err := r.UsedFuncs("index")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lower", "title", "trim", "upper"}, used)
+
+	_, err = renderResponse(r, "index", map[string]interface{}{"name": "a", "items": []int{}})
+	assert.NoError(t, err)
+	used, err = r.UsedFuncs("index")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lower", "title", "trim", "upper"}, used)
+
+	_, err = r.UsedFuncs("missing")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestWithFuncCheck(t *testing.T) {
+	r := New(WithFuncCheck())
+	r.AddFromStringsFuncs("index", template.FuncMap{"upper": strings.ToUpper}, `{{ upper .name | printf "%s" }}`)
+
+	assert.PanicsWithError(t, "template broken calls undefined functions: lower, trim", func() {
+		r.AddFromStringsFuncs("broken", template.FuncMap{"upper": strings.ToUpper},
+			`{{ upper .name }}{{ lower .name }}`, `{{ define "sub" }}{{ trim . }}{{ end }}`)
+	})
+
+	dynamic := NewDynamic(WithFuncCheck())
+	dynamic.AddFromFiles("index", "tests/base.html", "tests/article.html")
+	dynamic["broken"] = &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   "broken",
+		templateString: "{{ missing }}",
+		settings:       &dynamic.registry().opts,
+	}
+	err := dynamic.Validate()
+	assert.EqualError(t, err, "template broken: template broken calls undefined functions: missing")
+}
+
+func TestWithAllowedFuncs(t *testing.T) {
+	funcMap := template.FuncMap{"upper": strings.ToUpper, "exec": func(string) string { return "" }}
+	r := New(WithAllowedFuncs([]string{"upper"}))
+	r.AddFromStringsFuncs("index", funcMap, `{{ upper .name | printf "%s" }}`)
+
+	assert.PanicsWithError(t, "template tenant calls functions that are not allowed: exec", func() {
+		r.AddFromStringsFuncs("tenant", funcMap, `{{ upper .name }}{{ exec "rm" }}`)
+	})
+	assert.PanicsWithError(t, "template text calls functions that are not allowed: exec", func() {
+		r.AddTrustedFromString("text", `{{ exec "rm" }}`)
+	})
+	assert.PanicsWithError(t, "template caller calls functions that are not allowed: call", func() {
+		r.AddFromString("caller", `{{ call .fn }}`)
+	})
+
+	allowed := New(WithAllowedFuncs([]string{"call"}))
+	allowed.AddFromString("caller", `{{ call .fn }}`)
+}
+
+func TestWithAllowedFuncsParsesOnce(t *testing.T) {
+	funcMap := template.FuncMap{"upper": strings.ToUpper}
+	opens := func(opts ...RendererOption) int {
+		fsys := &countingFS{FS: fstest.MapFS{"index.html": {Data: []byte(`{{ upper .name }}`)}}}
+		r := NewDynamic(opts...)
+		r.AddFromFSFuncs("index", funcMap, fsys, "index.html")
+		opened := fsys.opened
+		_, err := r.Build("index")
+		assert.NoError(t, err)
+		return fsys.opened - opened
+	}
+
+	// the allowlist is checked on the parsed template, without reading the
+	// sources again
+	assert.Equal(t, opens(), opens(WithAllowedFuncs([]string{"upper"})))
+}
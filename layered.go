@@ -0,0 +1,69 @@
+package multitemplate
+
+import (
+	"errors"
+	"html/template"
+	"io/fs"
+	"sort"
+)
+
+// layeredFS resolves every file from the first of its layers that contains
+// it, so earlier layers shadow later ones, see AddFromLayeredFS.
+type layeredFS []fs.FS
+
+var _ fs.ReadDirFS = layeredFS(nil)
+
+// Open opens name from the first layer that contains it.
+func (l layeredFS) Open(name string) (fs.File, error) {
+	for _, layer := range l {
+		f, err := layer.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the entries of the directory name in every layer, so
+// patterns match the files of all layers. Entries of earlier layers win.
+func (l layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	found := false
+	for _, layer := range l {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if !seen[entry.Name()] {
+				seen[entry.Name()] = true
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// AddFromLayeredFS supply add template from the files (fs.Glob patterns) of
+// layers, e.g. the embed.FS of an app followed by the one of a theme it
+// builds on. Every file is read from the first layer that contains it, so
+// files of earlier layers override those of later ones with the same path.
+func (r Render) AddFromLayeredFS(name string, layers []fs.FS, files ...string) *template.Template {
+	return r.AddFromFS(name, layeredFS(layers), files...)
+}
+
+// AddFromLayeredFS supply add template from the files (fs.Glob patterns) of
+// layers, see Render.AddFromLayeredFS.
+func (r DynamicRender) AddFromLayeredFS(name string, layers []fs.FS, files ...string) *template.Template {
+	return r.AddFromFS(name, layeredFS(layers), files...)
+}
@@ -0,0 +1,41 @@
+package multitemplate
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddFromLayeredFS(t *testing.T) {
+	app := fstest.MapFS{
+		"templates/header.html": {Data: []byte(`{{ define "header" }}App {{ .title }}{{ end }}`)},
+	}
+	library := fstest.MapFS{
+		"templates/base.html":   {Data: []byte(`{{ template "header" . }} | {{ template "footer" }}`)},
+		"templates/header.html": {Data: []byte(`{{ define "header" }}Library {{ .title }}{{ end }}`)},
+		"templates/footer.html": {Data: []byte(`{{ define "footer" }}Library footer{{ end }}`)},
+	}
+
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromLayeredFS("index", []fs.FS{app, library},
+			"templates/base.html", "templates/header.html", "templates/footer.html")
+		r.AddFromLayeredFS("glob", []fs.FS{app, library}, "templates/*.html")
+		r.AddFromLayeredFS("library", []fs.FS{library}, "templates/base.html", "templates/*er.html")
+
+		w, err := renderResponse(r, "index", gin.H{"title": "Home"})
+		assert.NoError(t, err)
+		assert.Equal(t, "App Home | Library footer", w.Body.String())
+
+		w, err = renderResponse(r, "library", gin.H{"title": "Home"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Library Home | Library footer", w.Body.String())
+
+		defined, err := r.DefinedTemplates("glob")
+		assert.NoError(t, err)
+		assert.Contains(t, defined, "header")
+		assert.Contains(t, defined, "footer")
+	}
+}
@@ -0,0 +1,135 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"sync"
+)
+
+// lazyTemplate builds a template with its factory on first use and keeps it.
+type lazyTemplate struct {
+	mu      sync.Mutex
+	factory func() (*template.Template, error)
+	tmpl    *template.Template
+	// sum is the checksum of the sources the factory built the template
+	// from, see newFSGlobTemplate.
+	sum string
+}
+
+// get returns the kept template, calling the factory if there is none yet.
+// A failed factory call is not kept and is retried on the next call.
+func (l *lazyTemplate) get(name string) (*template.Template, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tmpl != nil {
+		return l.tmpl, nil
+	}
+	tmpl, err := callFactory(name, l.factory)
+	if err != nil {
+		return nil, err
+	}
+	l.tmpl = tmpl
+	return tmpl, nil
+}
+
+// checksum returns the checksum of the sources of the kept template, or ""
+// if there is none.
+func (l *lazyTemplate) checksum() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sum
+}
+
+func callFactory(name string, factory func() (*template.Template, error)) (*template.Template, error) {
+	tmpl, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("template %s: factory returned no template", name)
+	}
+	return tmpl, nil
+}
+
+// AddLazy registers a template built by factory when it is first rendered
+// instead of when it is added, e.g. for expensive templates that are rarely
+// used. A static renderer calls factory once and keeps its template; a
+// dynamic renderer calls it on every render. ReloadAll discards the kept
+// template so that factory is called again on the next render.
+func (r Render) AddLazy(name string, factory func() (*template.Template, error)) {
+	r.register(name, newLazyBuilder(name, factory))
+}
+
+// AddLazy registers a template built by factory on every render, see Render.AddLazy
+func (r DynamicRender) AddLazy(name string, factory func() (*template.Template, error)) {
+	if len(name) == 0 {
+		panic("template name cannot be empty")
+	}
+	r.register(name, newLazyBuilder(name, factory))
+}
+
+func newLazyBuilder(name string, factory func() (*template.Template, error)) *templateBuilder {
+	if factory == nil {
+		panic("template factory cannot be nil")
+	}
+	return &templateBuilder{
+		buildType:    lazyTemplateType,
+		templateName: name,
+		lazy:         &lazyTemplate{factory: factory},
+		options:      *NewTemplateOptions(),
+	}
+}
+
+// AddFromFSGlob supply add template from the files of fs.FS matching pattern.
+// Unlike AddFromFS the pattern is expanded and the files are parsed when the
+// template is first rendered, not when it is added, for file systems that are
+// expensive to enumerate. Like AddLazy it is built once by a static renderer
+// and on every render by a dynamic one.
+func (r Render) AddFromFSGlob(name string, fsys fs.FS, pattern string) {
+	r.add(name, newFSGlobBuilder(name, fsys, pattern))
+}
+
+// AddFromFSGlob supply add template from the files of fs.FS matching pattern, see Render.AddFromFSGlob
+func (r DynamicRender) AddFromFSGlob(name string, fsys fs.FS, pattern string) {
+	if len(name) == 0 {
+		panic("template name cannot be empty")
+	}
+	r.register(name, newFSGlobBuilder(name, fsys, pattern))
+}
+
+func newFSGlobBuilder(name string, fsys fs.FS, pattern string) *templateBuilder {
+	return &templateBuilder{
+		buildType:    fsGlobTemplateType,
+		templateName: name,
+		fsys:         fsys,
+		files:        []string{pattern},
+		options:      *NewTemplateOptions(),
+	}
+}
+
+// newFSGlobTemplate returns the template of the fs.FS glob builder tb, built
+// from its sources on first use like AddFromFS. With WithChecksumReload the
+// checksum of the sources is kept along with it.
+func newFSGlobTemplate(tb templateBuilder) *lazyTemplate {
+	lazy := &lazyTemplate{}
+	lazy.factory = func() (*template.Template, error) {
+		sources, err := tb.sources()
+		if err != nil {
+			return nil, err
+		}
+		builder := tb
+		builder.loaded = sources
+		tmpl, err := builder.build()
+		if err != nil {
+			return nil, err
+		}
+		// the factory is called by get with lazy.mu held
+		if tb.settings != nil && tb.settings.checksumReload {
+			lazy.sum = sourcesChecksum(sources)
+		}
+		return tmpl, nil
+	}
+	return lazy
+}
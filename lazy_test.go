@@ -0,0 +1,150 @@
+package multitemplate
+
+import (
+	"errors"
+	"html/template"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddLazy(t *testing.T) {
+	calls := 0
+	r := New()
+	r.AddLazy("index", func() (*template.Template, error) {
+		calls++
+		return template.New("index").Parse("Welcome to {{ .name }} template")
+	})
+	assert.Equal(t, 0, calls)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "index", gin.H{"name": "lazy"})
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, "Welcome to lazy template", w.Body.String())
+	performRequest(router)
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, r.ReloadAll())
+	assert.Equal(t, 1, calls)
+	performRequest(router)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAddLazyDynamic(t *testing.T) {
+	calls := 0
+	r := NewDynamic()
+	r.AddLazy("index", func() (*template.Template, error) {
+		calls++
+		return template.New("index").Parse("lazy")
+	})
+
+	_, err := r.Build("index")
+	assert.NoError(t, err)
+	_, err = r.Build("index")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAddLazyError(t *testing.T) {
+	errFactory := errors.New("factory failed")
+	fail := true
+	r := New()
+	r.AddLazy("index", func() (*template.Template, error) {
+		if fail {
+			return nil, errFactory
+		}
+		return template.New("index").Parse("lazy")
+	})
+
+	_, err := r.Build("index")
+	assert.ErrorIs(t, err, errFactory)
+
+	fail = false
+	_, err = r.Build("index")
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() { r.AddLazy("nil", nil) })
+}
+
+// countingFS counts the files opened in the wrapped file system.
+type countingFS struct {
+	fs.FS
+	opened int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.opened++
+	return c.FS.Open(name)
+}
+
+func TestAddFromFSGlob(t *testing.T) {
+	fsys := &countingFS{FS: fstest.MapFS{
+		"views/home.html":    {Data: []byte(`{{ template "footer" }}Welcome to {{ .name }} template`)},
+		"views/partial.html": {Data: []byte(`{{ define "footer" }}footer {{ end }}`)},
+	}}
+
+	static := New()
+	static.AddFromFSGlob("index", fsys, "views/*.html")
+	assert.Equal(t, 0, fsys.opened)
+
+	for i := 0; i < 2; i++ {
+		w, err := renderResponse(static, "index", gin.H{"name": "glob"})
+		assert.NoError(t, err)
+		assert.Equal(t, "footer Welcome to glob template", w.Body.String())
+	}
+	opened := fsys.opened
+	assert.NotZero(t, opened)
+
+	dynamic := NewDynamic()
+	dynamic.AddFromFSGlob("index", fsys, "views/*.html")
+	_, err := dynamic.Build("index")
+	assert.NoError(t, err)
+	assert.Greater(t, fsys.opened, opened)
+
+	dynamic.AddFromFSGlob("missing", fsys, "partials/*.html")
+	_, err = dynamic.Build("missing")
+	assert.EqualError(t, err, `template missing: pattern "partials/*.html" matches no files`)
+}
+
+func TestAddFromFSGlobSources(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/home.html":    {Data: []byte(`{{ template "footer" }}Welcome`)},
+		"views/partial.html": {Data: []byte(`{{ define "footer" }}footer {{ end }}`)},
+	}
+
+	r := New(WithChecksumReload())
+	r.AddFromFSGlob("index", fsys, "views/*.html")
+	assert.Equal(t, []string{"index"}, r.TemplatesForFile("views/partial.html"))
+
+	bundles, err := r.ExportSources()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"views/home.html", "views/partial.html"}, bundles["index"].Files)
+	assert.Equal(t, "fs_glob", bundles["index"].Type)
+
+	dump, err := r.DumpJSON()
+	assert.NoError(t, err)
+	assert.Contains(t, string(dump), `"defined":["footer","home.html","partial.html"]`)
+
+	// the checksum is kept once the template is built
+	_, err = renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	changed, err := r.HasChanged("index")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	fsys["views/home.html"] = &fstest.MapFile{Data: []byte(`{{ template "footer" }}Hello`)}
+	changed, err = r.HasChanged("index")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, r.ReloadAll())
+	w, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "footer Hello", w.Body.String())
+}
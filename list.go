@@ -0,0 +1,134 @@
+package multitemplate
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// listPlaceholder is the output of the listRows template function, replaced
+// with the rows by InstanceList.
+const listPlaceholder = "<!--multitemplate:rows-->"
+
+// listFlushRows is the number of rows InstanceList writes between two
+// flushes of the response.
+const listFlushRows = 100
+
+// listRows is the listRows template function, which marks where InstanceList
+// streams the rows of the list.
+func listRows() template.HTML {
+	return listPlaceholder
+}
+
+// listRender executes the outer template around the placeholder and streams
+// every item through the row template in between.
+type listRender struct {
+	outer executor
+	row   executor
+	items []interface{}
+	data  interface{}
+	// rowData, if set, returns the data of the row template for an item.
+	rowData func(item interface{}) interface{}
+	// err is the error looking up or building the templates, returned by Render.
+	err error
+	// failed records render errors, see RecentErrors.
+	failed func(err error)
+	// contentType replaces the HTML Content-Type if set.
+	contentType string
+}
+
+var _ render.Render = listRender{}
+
+// Render (listRender) writes the outer template with the rows in place of the placeholder.
+func (r listRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+
+	err := r.render(w)
+	if err != nil && r.failed != nil {
+		r.failed(err)
+	}
+	return err
+}
+
+func (r listRender) render(w http.ResponseWriter) error {
+	if r.err != nil {
+		return r.err
+	}
+	var outer bytes.Buffer
+	if err := r.outer.Execute(&outer, r.data); err != nil {
+		return err
+	}
+	head, tail, ok := bytes.Cut(outer.Bytes(), []byte(listPlaceholder))
+	if !ok || bytes.Contains(tail, []byte(listPlaceholder)) {
+		return fmt.Errorf("outer template must call listRows exactly once")
+	}
+
+	buf := bufio.NewWriter(w)
+	if _, err := buf.Write(head); err != nil {
+		return err
+	}
+	for i, item := range r.items {
+		if r.rowData != nil {
+			item = r.rowData(item)
+		}
+		if err := r.row.Execute(buf, item); err != nil {
+			return err
+		}
+		if (i+1)%listFlushRows == 0 {
+			if err := flushList(buf, w); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := buf.Write(tail); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// flushList sends the rows buffered in buf to the client, so it receives
+// long lists while they are still being rendered.
+func flushList(buf *bufio.Writer, w http.ResponseWriter) error {
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// WriteContentType (listRender) writes HTML ContentType.
+func (r listRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		if r.contentType != "" {
+			header["Content-Type"] = []string{r.contentType}
+		} else {
+			header["Content-Type"] = htmlContentType
+		}
+	}
+}
+
+// InstanceList implements Render.InstanceList and DynamicRender.InstanceList.
+func (r *registry) InstanceList(outer, row string, items []interface{}, data interface{}) render.Render {
+	outer, row = r.resolve(nil, outer), r.resolve(nil, row)
+	list := listRender{
+		items:       items,
+		data:        data,
+		failed:      func(err error) { r.recentErrors.add(outer, err) },
+		contentType: r.contentType(outer),
+	}
+	if hook := r.opts.dataHook; hook != nil {
+		list.data = hook(nil, outer, data)
+		list.rowData = func(item interface{}) interface{} { return hook(nil, row, item) }
+	}
+	if list.outer, list.err = r.executable(nil, outer); list.err == nil {
+		list.row, list.err = r.executable(nil, row)
+	}
+	return r.headers(outer, r.wrapWriter(list))
+}
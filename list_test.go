@@ -0,0 +1,67 @@
+package multitemplate
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceList(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromString("table", `<h1>{{ .title }}</h1><table>{{ listRows }}</table>`)
+		r.AddFromString("row", `<tr><td>{{ .name }}</td></tr>`)
+		r.AddFromString("norows", `<table></table>`)
+
+		items := []interface{}{gin.H{"name": "a"}, gin.H{"name": "<b>"}}
+		w := httptest.NewRecorder()
+		err := r.InstanceList("table", "row", items, gin.H{"title": "Users"}).Render(w)
+		assert.NoError(t, err)
+		assert.Equal(t, "<h1>Users</h1><table><tr><td>a</td></tr><tr><td>&lt;b&gt;</td></tr></table>", w.Body.String())
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+		w = httptest.NewRecorder()
+		err = r.InstanceList("norows", "row", items, nil).Render(w)
+		assert.EqualError(t, err, "outer template must call listRows exactly once")
+		assert.Empty(t, w.Body.String())
+
+		w = httptest.NewRecorder()
+		err = r.InstanceList("table", "missing", items, nil).Render(w)
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+		assert.Empty(t, w.Body.String())
+	}
+}
+
+func TestInstanceListFlush(t *testing.T) {
+	r := New()
+	r.AddFromString("list", `<ul>{{ listRows }}</ul>`)
+	r.AddFromString("row", `<li>{{ . }}</li>`)
+
+	items := make([]interface{}, listFlushRows+1)
+	for i := range items {
+		items[i] = i
+	}
+	w := httptest.NewRecorder()
+	assert.NoError(t, r.InstanceList("list", "row", items, nil).Render(w))
+	assert.True(t, w.Flushed)
+	assert.True(t, strings.HasSuffix(w.Body.String(), "<li>100</li></ul>"))
+}
+
+func TestInstanceListResolveAndHook(t *testing.T) {
+	var hooked []string
+	r := New(WithDataHook(func(_ *gin.Context, name string, data interface{}) interface{} {
+		hooked = append(hooked, name)
+		return data
+	}))
+	r.AddFromString("table.v2", `<table>{{ listRows }}</table>`)
+	r.AddFromString("row.v2", `<tr>{{ . }}</tr>`)
+	r.SetResolver(func(name string, _ *gin.Context) string { return name + ".v2" })
+
+	w := httptest.NewRecorder()
+	err := r.InstanceList("table", "row", []interface{}{"a", "b"}, nil).Render(w)
+	assert.NoError(t, err)
+	assert.Equal(t, "<table><tr>a</tr><tr>b</tr></table>", w.Body.String())
+	assert.Equal(t, []string{"table.v2", "row.v2", "row.v2"}, hooked)
+}
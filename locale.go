@@ -0,0 +1,120 @@
+package multitemplate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// defaultLocale is the fallback locale of localized templates unless
+// WithDefaultLocale sets another one.
+const defaultLocale = "en"
+
+// normalizeLocale returns locale with "_" replaced by "-", e.g. "de-AT" for
+// "de_AT".
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(locale, "_", "-")
+}
+
+// fallbackLocale returns the locale rendered if a localized template has
+// not the requested one.
+func (r *registry) fallbackLocale() string {
+	if r.opts.defaultLocale != "" {
+		return normalizeLocale(r.opts.defaultLocale)
+	}
+	return defaultLocale
+}
+
+// localized builds a variant of the template name for every file of
+// filesByLocale, records them as its locales and returns a builder of the
+// variant of the default locale, or of the first locale if it has not the
+// default one, to register under name.
+func (r *registry) localized(name string, filesByLocale map[string]string) *templateBuilder {
+	if len(filesByLocale) == 0 {
+		panic(fmt.Sprintf("template %s has no locales", name))
+	}
+
+	variants := make(map[string]*templateBuilder, len(filesByLocale))
+	for _, locale := range sortedNames(filesByLocale) {
+		builder := &templateBuilder{
+			buildType:    filesTemplateType,
+			templateName: name,
+			files:        []string{filesByLocale[locale]},
+			options:      *NewTemplateOptions(),
+			settings:     &r.opts,
+		}
+		var err error
+		if r.dynamic {
+			_, err = builder.buildExecutor()
+		} else {
+			err = builder.keep()
+		}
+		if err != nil {
+			panic(err)
+		}
+		variants[normalizeLocale(locale)] = builder
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.builders[name]; ok {
+		panic(fmt.Sprintf("template %s already exists", name))
+	}
+	if r.locales == nil {
+		r.locales = make(map[string]map[string]*templateBuilder)
+	}
+	r.locales[name] = variants
+
+	fallback, ok := variants[r.fallbackLocale()]
+	if !ok {
+		fallback = variants[sortedNames(variants)[0]]
+	}
+	builder := *fallback
+	return &builder
+}
+
+// localeVariant returns the locale of the variant of the localized template
+// name to render for locale: the locale itself, its language (e.g. "de" for
+// "de-AT" or "de_AT"), or the default locale.
+func (r *registry) localeVariant(name, locale string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	locales, ok := r.locales[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	locale = normalizeLocale(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	fallback := r.fallbackLocale()
+	for _, candidate := range []string{locale, language, fallback} {
+		if _, ok := locales[candidate]; ok {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s has no locale %s nor default locale %s", ErrTemplateNotFound, name, locale, fallback)
+}
+
+// InstanceLocale implements Render.InstanceLocale and DynamicRender.InstanceLocale.
+func (r *registry) InstanceLocale(name, locale string, data interface{}) render.Render {
+	name = r.resolve(nil, name)
+	variant, err := r.localeVariant(name, locale)
+	if err != nil {
+		panic(err)
+	}
+	// the variants share the name, so their output is not cached
+	return r.headers(name, r.instance(nil, name, data, instanceOptions{locale: variant, uncached: true}))
+}
+
+// AddLocalized supply add a template with one file per locale (e.g. "en", "de"),
+// rendered with InstanceLocale. Instance renders the default locale.
+func (r Render) AddLocalized(name string, filesByLocale map[string]string) {
+	r.register(name, r.registry().localized(name, filesByLocale))
+}
+
+// AddLocalized supply add a template with one file per locale (e.g. "en", "de"),
+// rendered with InstanceLocale. Instance renders the default locale.
+func (r DynamicRender) AddLocalized(name string, filesByLocale map[string]string) {
+	r.register(name, r.registry().localized(name, filesByLocale))
+}
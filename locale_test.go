@@ -0,0 +1,74 @@
+package multitemplate
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var homeFiles = map[string]string{
+	"en": "tests/locale/home.en.html",
+	"de": "tests/locale/home.de.html",
+	"fr": "tests/locale/home.fr.html",
+}
+
+func TestInstanceLocale(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddLocalized("home", homeFiles)
+
+		for locale, expected := range map[string]string{
+			"de":    "Hallo Ann",
+			"de-AT": "Hallo Ann",
+			"de_AT": "Hallo Ann",
+			"fr":    "Bonjour Ann",
+			"it":    "Hello Ann",
+		} {
+			w := httptest.NewRecorder()
+			assert.NoError(t, r.InstanceLocale("home", locale, gin.H{"name": "Ann"}).Render(w))
+			assert.Equal(t, expected, w.Body.String())
+		}
+		assert.Panics(t, func() { r.InstanceLocale("missing", "en", nil) })
+
+		// the name renders the default locale, the variants are not registered
+		assert.True(t, r.Exists("home"))
+		assert.Equal(t, []string{"home"}, r.Names())
+		w := httptest.NewRecorder()
+		assert.NoError(t, r.Instance("home", gin.H{"name": "Ann"}).Render(w))
+		assert.Equal(t, "Hello Ann", w.Body.String())
+		assert.Panics(t, func() { r.AddLocalized("home", homeFiles) })
+	}
+}
+
+func TestWithDefaultLocale(t *testing.T) {
+	r := New(WithDefaultLocale("fr"))
+	r.AddLocalized("home", map[string]string{"de": homeFiles["de"], "fr": homeFiles["fr"]})
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, r.InstanceLocale("home", "en-US", gin.H{"name": "Ann"}).Render(w))
+	assert.Equal(t, "Bonjour Ann", w.Body.String())
+
+	r = New()
+	r.AddLocalized("home", map[string]string{"de": homeFiles["de"]})
+	assert.Panics(t, func() { r.InstanceLocale("home", "fr", nil) })
+	assert.Panics(t, func() { r.AddLocalized("empty", nil) })
+}
+
+func TestReloadLocalized(t *testing.T) {
+	dir := t.TempDir()
+	en, de := filepath.Join(dir, "home.en.html"), filepath.Join(dir, "home.de.html")
+	assert.NoError(t, os.WriteFile(en, []byte("Hello"), 0o600))
+	assert.NoError(t, os.WriteFile(de, []byte("Hallo"), 0o600))
+
+	r := New()
+	r.AddLocalized("home", map[string]string{"en": en, "de": de})
+	assert.NoError(t, os.WriteFile(de, []byte("Guten Tag"), 0o600))
+	assert.NoError(t, r.ReloadAll())
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, r.InstanceLocale("home", "de", nil).Render(w))
+	assert.Equal(t, "Guten Tag", w.Body.String())
+}
@@ -0,0 +1,86 @@
+package multitemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolveManifest returns the files of every page of manifest: the page
+// itself followed by its dependencies, transitive ones included, in the order
+// they are declared. Pages are the entries no other entry depends on.
+func resolveManifest(manifest map[string][]string) (map[string][]string, error) {
+	dependency := make(map[string]bool)
+	for _, deps := range manifest {
+		for _, dep := range deps {
+			dependency[dep] = true
+		}
+	}
+
+	pages := make(map[string][]string)
+	for _, page := range sortedNames(manifest) {
+		if dependency[page] {
+			continue
+		}
+		var files []string
+		if err := collectDependencies(manifest, page, nil, make(map[string]bool), &files); err != nil {
+			return nil, err
+		}
+		pages[page] = files
+	}
+	if len(manifest) > 0 && len(pages) == 0 {
+		return nil, fmt.Errorf("template manifest has no pages, every entry is a dependency")
+	}
+	return pages, nil
+}
+
+// collectDependencies appends file and its dependencies to files, depth first.
+// path holds the files being collected, to report dependency cycles.
+func collectDependencies(manifest map[string][]string, file string, path []string, seen map[string]bool, files *[]string) error {
+	for _, p := range path {
+		if p == file {
+			return fmt.Errorf("template manifest has a dependency cycle: %s", strings.Join(append(path, file), " -> "))
+		}
+	}
+	if seen[file] {
+		return nil
+	}
+	seen[file] = true
+	*files = append(*files, file)
+
+	path = append(path, file)
+	for _, dep := range manifest[file] {
+		if err := collectDependencies(manifest, dep, path, seen, files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFromManifest registers every page of manifest with add.
+func addFromManifest(manifest map[string][]string, add func(name string, files ...string)) {
+	pages, err := resolveManifest(manifest)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, page := range sortedNames(pages) {
+		add(page, pages[page]...)
+	}
+}
+
+// AddFromManifest supply add a template for every page of a dependency
+// manifest mapping template files to the files they depend on, e.g.
+// {"page.html": {"header.html", "footer.html"}}. Every entry that no other
+// entry depends on is a page, registered under its path and built from itself
+// followed by its dependencies, transitive ones included. The page file is the
+// root template executed by Instance. Dependency cycles panic, like any other
+// template that fails to build.
+func (r Render) AddFromManifest(manifest map[string][]string) {
+	addFromManifest(manifest, func(name string, files ...string) { r.AddFromFiles(name, files...) })
+}
+
+// AddFromManifest supply add a template for every page of a dependency manifest,
+// see Render.AddFromManifest
+func (r DynamicRender) AddFromManifest(manifest map[string][]string) {
+	addFromManifest(manifest, func(name string, files ...string) { r.AddFromFiles(name, files...) })
+}
@@ -0,0 +1,56 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var testManifest = map[string][]string{
+	"tests/manifest/page.html":   {"tests/manifest/header.html", "tests/manifest/footer.html"},
+	"tests/manifest/header.html": {"tests/manifest/nav.html"},
+}
+
+func TestAddFromManifest(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromManifest(testManifest)
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "tests/manifest/page.html", gin.H{"title": "Manifest"})
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "<header><nav>home</nav></header><main>Manifest</main><footer>bye</footer>\n", w.Body.String())
+
+		names, err := r.DefinedTemplates("tests/manifest/page.html")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"footer.html", "header.html", "nav.html", "page.html"}, names)
+
+		_, err = r.Build("tests/manifest/header.html")
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+	}
+}
+
+func TestResolveManifest(t *testing.T) {
+	pages, err := resolveManifest(map[string][]string{
+		"page": {"a", "b"},
+		"a":    {"b", "c"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"page": {"page", "a", "b", "c"}}, pages)
+
+	_, err = resolveManifest(map[string][]string{
+		"page": {"a"},
+		"a":    {"b"},
+		"b":    {"a"},
+	})
+	assert.EqualError(t, err, "template manifest has a dependency cycle: page -> a -> b -> a")
+
+	assert.Panics(t, func() {
+		New().AddFromManifest(map[string][]string{"a": {"b"}, "b": {"a"}})
+	})
+}
@@ -0,0 +1,92 @@
+package multitemplate
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// mergeData returns a new map holding the fields of base overridden by
+// extra. base may be nil, a map with string keys or a struct (or a pointer
+// to one), whose exported fields are copied under their names. Neither base
+// nor extra is modified.
+func mergeData(base interface{}, extra map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(extra))
+
+	v := reflect.ValueOf(base)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Pointer, reflect.Interface:
+		// nil base
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot merge data of type %T: map keys are not strings", base)
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			merged[iter.Key().String()] = iter.Value().Interface()
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).IsExported() {
+				merged[t.Field(i).Name] = v.Field(i).Interface()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cannot merge data of type %T", base)
+	}
+
+	for key, value := range extra {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// withRequestData returns data with the request values added for every key
+// it does not set itself, see WithRequestData.
+func withRequestData(data interface{}, values map[string]interface{}) interface{} {
+	if len(values) == 0 {
+		return data
+	}
+	merged, err := mergeData(data, nil)
+	if err != nil {
+		panic(err)
+	}
+	for key, value := range values {
+		if _, ok := merged[key]; !ok {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// InstanceMerge implements Render.InstanceMerge and DynamicRender.InstanceMerge.
+func (r *registry) InstanceMerge(name string, base interface{}, extra map[string]interface{}) render.Render {
+	data, err := mergeData(base, extra)
+	if err != nil {
+		panic(err)
+	}
+	return r.Instance(name, data)
+}
+
+// InstanceMulti implements Render.InstanceMulti and DynamicRender.InstanceMulti.
+func (r *registry) InstanceMulti(name string, datas ...interface{}) render.Render {
+	merged := make(map[string]interface{})
+	for _, data := range datas {
+		fields, err := mergeData(data, nil)
+		if err != nil {
+			panic(err)
+		}
+		for key, value := range fields {
+			merged[key] = value
+		}
+	}
+	return r.Instance(name, merged)
+}
@@ -0,0 +1,92 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeData(t *testing.T) {
+	type page struct {
+		Title string
+		Name  string
+		count int
+	}
+	extra := map[string]interface{}{"Name": "user"}
+
+	merged, err := mergeData(&page{Title: "Home", Name: "page", count: 1}, extra)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Title": "Home", "Name": "user"}, merged)
+
+	base := gin.H{"title": "Home"}
+	merged, err = mergeData(base, map[string]interface{}{"user": "ann"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"title": "Home", "user": "ann"}, merged)
+	assert.Equal(t, gin.H{"title": "Home"}, base)
+
+	merged, err = mergeData(nil, extra)
+	assert.NoError(t, err)
+	assert.Equal(t, extra, merged)
+
+	_, err = mergeData(42, extra)
+	assert.Error(t, err)
+}
+
+func TestInstanceMerge(t *testing.T) {
+	r := createFromStringDynamic().(testRenderer)
+	base := gin.H{"name": "base"}
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceMerge("index", base, map[string]interface{}{"name": "merged"}))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, "Welcome to merged template", w.Body.String())
+	assert.Equal(t, gin.H{"name": "base"}, base)
+	assert.Panics(t, func() { r.InstanceMerge("index", []string{}, nil) })
+}
+
+func TestWithRequestData(t *testing.T) {
+	r := New(WithRequestData(func(c *gin.Context) map[string]interface{} {
+		return map[string]interface{}{"canonical": "https://example.com" + c.Request.URL.Path, "title": "Default"}
+	}))
+	r.AddFromString("index", `{{ .title }} {{ .canonical }}`)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceCtx(c, "index", gin.H{"title": "Home"}))
+	})
+	w := performRequest(router)
+	assert.Equal(t, "Home https://example.com/", w.Body.String())
+
+	router = gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "index", gin.H{"title": "Home"})
+	})
+	w = performRequest(router)
+	assert.Equal(t, "Home ", w.Body.String())
+}
+
+func TestInstanceMulti(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "{{ .Name }} {{ .title }} {{ .user }}")
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceMulti("index",
+			gin.H{"title": "Home", "user": "anonymous"},
+			nil,
+			struct{ Name string }{"Site"},
+			map[string]interface{}{"user": "gin"},
+		))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, "Site Home gin", w.Body.String())
+	assert.Panics(t, func() { r.InstanceMulti("index", 42) })
+}
@@ -0,0 +1,895 @@
+package multitemplate
+
+import (
+	"hash"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// BatchRender renders the named template once for every item and passes each
+// result to sink, e.g. to write static pages. Unlike Instance, the template is
+// built only once for the whole batch, even by a dynamic renderer. The reader
+// given to sink is only valid until sink returns. Rendering stops at the first
+// error returned by the template or by sink.
+func (r Render) BatchRender(name string, items []interface{}, sink func(i int, r io.Reader) error) error {
+	return r.registry().BatchRender(name, items, sink)
+}
+
+// UseBundle merges the functions of b, and of the bundles it requires, into
+// the functions every template is parsed with. Functions given at
+// registration take precedence. It panics if a function the bundles need is
+// not defined by them or an earlier bundle. A static renderer applies the
+// functions to templates added afterwards.
+func (r Render) UseBundle(b FuncBundle) {
+	r.registry().UseBundle(b)
+}
+
+// HasChanged reports whether the content of the source files of the template
+// registered under name changed since it was last built, e.g. to drive
+// reloads from a file watcher without rebuilding templates whose files were
+// only touched. Templates without source files, such as string templates,
+// never change. The checksum of the sources is only kept with
+// WithChecksumReload; without it templates with source files always report a
+// change, as do the templates of a dynamic renderer that are not pinned,
+// which are rebuilt on every render.
+func (r Render) HasChanged(name string) (bool, error) {
+	return r.registry().HasChanged(name)
+}
+
+// RenderHash renders the template registered under name with data and
+// returns the hex encoded SHA-256 of the output, e.g. to record the output
+// for a fixture and compare it after templates were reloaded.
+func (r Render) RenderHash(name string, data interface{}) (string, error) {
+	return r.registry().RenderHash(name, data)
+}
+
+// DiffRenders reports whether rendering data with the template registered
+// under name produces a different output than with oldTemplate, typically
+// the copy returned by Build before a reload. oldTemplate is executed, so it
+// must not be a template shared with a renderer:
+//
+//	old, _ := r.Build("index")
+//	_ = r.ReloadAll()
+//	changed, err := r.DiffRenders("index", fixture, old)
+func (r Render) DiffRenders(name string, data interface{}, oldTemplate *template.Template) (bool, error) {
+	return r.registry().DiffRenders(name, data, oldTemplate)
+}
+
+// DryRun executes the template registered under name with data, discarding
+// the output, and returns the first error, e.g. in tests to catch templates
+// accessing fields the data does not have. Unlike a normal render, missing
+// map keys are errors instead of "<no value>". The template is built afresh
+// from its sources, so the renderer is not affected. Templates added as a
+// *template.Template or by a factory are cloned, which fails once they were
+// executed. Engine templates are executed as they are.
+func (r Render) DryRun(name string, data interface{}) error {
+	return r.registry().DryRun(name, data)
+}
+
+// RenderEmail renders the template registered under name with data for an
+// HTML email: the CSS of its <style> elements is inlined into the style
+// attributes of the elements it applies to, as most email clients ignore
+// style sheets. The inliner supports element, class and id selectors and
+// their combinations, e.g. "td.total"; other rules, such as @media queries,
+// are left in the <style> element.
+func (r Render) RenderEmail(name string, data interface{}) (string, error) {
+	return r.registry().RenderEmail(name, data)
+}
+
+// InstanceEmpty returns a render that writes no body, only the given headers
+// and a 204 No Content status, or the status set with WithEmptyStatus, e.g.
+// for an HTMX endpoint that only triggers a client event with HX-Trigger.
+func (r Render) InstanceEmpty(headers map[string]string) render.Render {
+	return r.registry().InstanceEmpty(headers)
+}
+
+// RenderErrorPage aborts the request with a 500 Internal Server Error after a
+// failed render of the template registered under name. In debug mode it
+// responds with an HTML page showing the template name, the error and, when
+// the error carries a location in a file or string of the template, the
+// source lines around it. In release mode only the status is sent, so no
+// template internals leak. In both modes err is added to c.Errors.
+func (r Render) RenderErrorPage(c *gin.Context, name string, err error) {
+	r.registry().RenderErrorPage(c, name, err)
+}
+
+// InstanceError renders the error page added with AddErrorPage for status,
+// always as text/html and with status as the response status, whatever the
+// handler passed to c.Render. Without an error page for status only the
+// status is written.
+func (r Render) InstanceError(status int, data interface{}) render.Render {
+	return r.registry().InstanceError(status, data)
+}
+
+// InstanceETag works like InstanceCtx but sets a weak ETag computed from the
+// version token etag, e.g. the last modification of the data, instead of
+// hashing the rendered body. If the If-None-Match header of the request c
+// matches it, nothing is rendered and only a 304 status is written, so the
+// template is neither built nor executed. The request is needed to read the
+// header, so c must not be nil. It panics if etag has a character not allowed
+// in an entity tag, such as a quote or a space.
+func (r Render) InstanceETag(c *gin.Context, name string, data interface{}, etag string) render.Render {
+	return r.registry().InstanceETag(c, name, data, etag)
+}
+
+// RenderToWriter renders the template registered under name with data into
+// w, e.g. a file or an email body, outside of a request.
+func (r Render) RenderToWriter(w io.Writer, name string, data interface{}) error {
+	return r.registry().RenderToWriter(w, name, data)
+}
+
+// ExportStatic renders every job into a file below dir, creating the
+// directories it needs, e.g. to generate a static site. It stops at the first
+// job that fails and returns its error.
+func (r Render) ExportStatic(dir string, jobs []ExportJob) error {
+	return r.registry().ExportStatic(dir, jobs)
+}
+
+// ExportSources returns the source of every registered template keyed by its
+// name, e.g. to back it up or move it to another environment: the bodies of
+// string templates, and the resolved paths and current contents of the files
+// of file, glob, fs.FS and zip templates. Sources are exported as written,
+// before front matter is split off or the source transform is applied, and
+// without the common files of the renderer. Templates added as a
+// *template.Template, a parse tree or with AddLazy only export their type. It
+// stops at the first template whose files cannot be read and returns its error.
+func (r Render) ExportSources() (map[string]SourceBundle, error) {
+	return r.registry().ExportSources()
+}
+
+// Meta returns the front matter of the template registered under name, see
+// WithFrontMatter: the metadata of its first file, or of its string, e.g. a
+// title, the roles required to see the page or cache hints. It is nil if the
+// template has none. A static renderer returns the metadata read when the
+// template was added or last reloaded.
+func (r Render) Meta(name string) (map[string]interface{}, error) {
+	return r.registry().Meta(name)
+}
+
+// SetCachePolicy sets the Cache-Control header sent with the template name,
+// e.g. "public, max-age=3600" for static content or "no-store" for pages
+// rendered per user. It overrides the default of WithCachePolicy and
+// WithNoStore. An empty policy removes the override.
+func (r Render) SetCachePolicy(name, policy string) {
+	r.registry().SetCachePolicy(name, policy)
+}
+
+// InstanceHydrate works like Instance but also embeds data as JSON for the
+// client to hydrate the server rendered page, in a
+// <script id="__data__" type="application/json"> element inserted before
+// the closing body tag, or appended if there is none. The JSON is escaped
+// for the script context, so data cannot close the element. Data that
+// cannot be encoded fails the render. The output is never cached.
+func (r Render) InstanceHydrate(name string, data interface{}) render.Render {
+	return r.registry().InstanceHydrate(name, data)
+}
+
+// Names returns the names of the registered templates, sorted.
+//
+// Every method enumerating templates, e.g. Names, DumpJSON, TemplatesForFile,
+// ReloadAll and Merge, visits them in name order, so their output and the
+// first error they report are the same on every run.
+func (r Render) Names() []string {
+	return r.registry().Names()
+}
+
+// MustNames panics unless exactly the expected templates are registered,
+// e.g. to check at startup that the name constants used by the handlers are
+// in sync with the templates loaded. The panic lists the missing and the
+// unexpected names.
+func (r Render) MustNames(expected ...string) {
+	r.registry().MustNames(expected...)
+}
+
+// AllDefinedTemplates builds every registered template and returns the
+// sorted names of the templates defined in its set, as DefinedTemplates
+// does, keyed by the name it is registered under, e.g. to list the blocks a
+// theme can override. Templates that fail to build are left out.
+func (r Render) AllDefinedTemplates() map[string][]string {
+	return r.registry().AllDefinedTemplates()
+}
+
+// ForEach builds every registered template, in name order, and calls fn with
+// its name and template until fn returns false. Templates that fail to build,
+// and trusted or Engine templates, are passed with a nil template; Build
+// returns their error. The registry is not locked while fn runs, so fn may
+// use the renderer, and templates added meanwhile are not visited.
+func (r Render) ForEach(fn func(name string, tmpl *template.Template) bool) {
+	r.registry().ForEach(fn)
+}
+
+// Funcs returns the functions the template registered under name is parsed
+// with: the renderer-wide functions (debug and context functions) merged with
+// the function map given at registration. The builtin functions of
+// text/template, such as "len" or "printf", are not included, nor are the
+// functions of templates added as a *template.Template or with AddLazy, which
+// cannot be read back from them. It returns nil if no template is registered
+// under name.
+func (r Render) Funcs(name string) template.FuncMap {
+	return r.registry().Funcs(name)
+}
+
+// DumpJSON serializes every registered template, its type, its sources and
+// the templates defined in its set to JSON, e.g. for a template explorer.
+// Templates that fail to build are included with their error.
+func (r Render) DumpJSON() ([]byte, error) {
+	return r.registry().DumpJSON()
+}
+
+// InstanceList renders the template registered under outer with data and
+// streams every item of items, in order, through the template registered
+// under row in place of the {{ listRows }} call of outer, e.g.
+//
+//	<table>{{ listRows }}</table>
+//
+// Only the outer template is buffered and the response is flushed every 100
+// rows, so large lists are sent without holding the whole body in memory.
+// Both names are resolved, see SetResolver, and the data hook runs for the
+// data of the outer template and for every item. A
+// template that is not registered or fails to build is reported by Render. A
+// failing row leaves a partial body, and WithPrettyHTML, WithPostProcessor and
+// the output cache do not apply.
+func (r Render) InstanceList(outer, row string, items []interface{}, data interface{}) render.Render {
+	return r.registry().InstanceList(outer, row, items, data)
+}
+
+// InstanceLocale works like Instance for a template added with AddLocalized,
+// rendering the file of locale, where "de_AT" stands for "de-AT". Without a
+// file for locale, e.g. "de-AT", the file of its language ("de") is used, then
+// the file of the default locale, see WithDefaultLocale. It panics if none of
+// them exists.
+func (r Render) InstanceLocale(name, locale string, data interface{}) render.Render {
+	return r.registry().InstanceLocale(name, locale, data)
+}
+
+// InstanceMerge works like Instance with the fields of base merged with
+// extra, e.g. page data with the user, locale or flash messages of the
+// request. The merge produces a new map for every render, so neither base
+// nor extra is modified and base can be shared between concurrent requests.
+// Keys of extra override the fields of base, see mergeData for the supported
+// types of base. Methods of a struct base are not available to the template.
+func (r Render) InstanceMerge(name string, base interface{}, extra map[string]interface{}) render.Render {
+	return r.registry().InstanceMerge(name, base, extra)
+}
+
+// InstanceMulti works like Instance with the fields of every value of datas
+// merged into one map, e.g. the data returned by several services. Later
+// values override the keys of earlier ones. Every value may be nil, a map
+// with string keys or a struct, like the base of InstanceMerge.
+func (r Render) InstanceMulti(name string, datas ...interface{}) render.Render {
+	return r.registry().InstanceMulti(name, datas...)
+}
+
+// SetCacheable flags the named templates as cacheable: with WithOutputCache,
+// their output is cached by template name and data, and renders with equal
+// data are served from the cache without executing the template. Only flag
+// templates whose output is a pure function of their data, as the request
+// and template functions are not part of the cache key. Data is compared by
+// its JSON encoding, and data that cannot be encoded is never cached.
+func (r Render) SetCacheable(names ...string) {
+	r.registry().SetCacheable(names...)
+}
+
+// InvalidateOutput removes the cached output of the template name, e.g.
+// after the data it was rendered from changed, see SetCacheable.
+func (r Render) InvalidateOutput(name string) {
+	r.registry().InvalidateOutput(name)
+}
+
+// RecentErrors returns the last render failures, oldest first, e.g. for a
+// monitoring dashboard. Failures are recorded by buffered renders (with a
+// timeout, WithPrettyHTML, WithPostProcessor, the output cache or trusted and
+// Engine templates) when executing or transforming a template fails, before
+// anything is written. Only the last 50 failures are kept.
+func (r Render) RecentErrors() []RenderError {
+	return r.registry().RecentErrors()
+}
+
+// Exists reports whether a template is registered under name.
+func (r Render) Exists(name string) bool {
+	return r.registry().Exists(name)
+}
+
+// Build returns the template registered under name. A dynamic renderer
+// builds it from its sources on every call; a static renderer returns a copy
+// of the template built at registration, so executing it does not affect
+// later renders. Unlike Instance, failures are returned as errors instead of
+// panics. Trusted and Engine templates are not html/template templates and
+// cannot be returned.
+func (r Render) Build(name string) (*template.Template, error) {
+	return r.registry().Build(name)
+}
+
+// DefinedTemplates builds the template registered under name and returns the
+// sorted names of all templates defined in its set, including its own.
+func (r Render) DefinedTemplates(name string) ([]string, error) {
+	return r.registry().DefinedTemplates(name)
+}
+
+// Merge copies all templates registered in other into r, along with their
+// locales, error pages, cache policies, timeouts and cacheable flags.
+// Templates from a static Render are added as-is and are not reloaded.
+// It returns an error without modifying r if any name, or the status of an
+// error page, is already registered.
+func (r Render) Merge(other Renderer) error {
+	reg := r.registry()
+	defer reg.mirrorTemplates(r)
+	return reg.Merge(other)
+}
+
+// SetCommonFiles sets files, e.g. a _helpers.html with shared {{define}}
+// macros, that are parsed before the files of every template built from the
+// OS file system (files, globs and sections), so pages can use them without
+// listing them. A static renderer applies them to templates added afterwards.
+func (r Render) SetCommonFiles(files ...string) {
+	r.registry().SetCommonFiles(files...)
+}
+
+// SetDebugFuncs sets functions, e.g. a data dump helper, that are only
+// available while gin is in debug mode. In release mode templates still parse
+// but every call returns zero values and renders nothing. The mode is checked
+// when a template is built, so a static renderer applies them to templates
+// added afterwards.
+func (r Render) SetDebugFuncs(funcMap template.FuncMap) {
+	r.registry().SetDebugFuncs(funcMap)
+}
+
+// SetFlagProvider makes the feature flags of fn available to templates
+// through the flag function, e.g. {{ if flag "new-checkout" }}, evaluated on
+// every render for its request. The context is nil unless the render was
+// created with InstanceCtx. Templates are cloned per render to bind the
+// request, like with WithContextFuncs. A static renderer applies it to
+// templates added afterwards. A nil fn removes the flag function.
+func (r Render) SetFlagProvider(fn func(name string, c *gin.Context) bool) {
+	r.registry().SetFlagProvider(fn)
+}
+
+// SetResolver sets fn to map the template name passed to Instance and the
+// other render methods to the template actually rendered, e.g. a variant for
+// the device type or the A/B test bucket of the request. The context is nil
+// unless the render was created with InstanceCtx. An empty result renders
+// name itself. A nil fn removes the resolver.
+func (r Render) SetResolver(fn func(name string, c *gin.Context) string) {
+	r.registry().SetResolver(fn)
+}
+
+// SetSRIManifest sets the subresource integrity values of the bundled
+// assets, e.g. "main.js" => "sha384-...", and makes them available to
+// templates through the sri function:
+//
+//	<script src="/main.js" integrity="{{ sri "main.js" }}"></script>
+//
+// Calling sri for an asset missing from the manifest fails the render. A
+// static renderer applies the manifest to templates added afterwards.
+func (r Render) SetSRIManifest(manifest map[string]string) {
+	r.registry().SetSRIManifest(manifest)
+}
+
+// TemplatesForFile returns the sorted names of the templates built from the
+// file at path, so a file watcher can rebuild only the affected templates.
+// Glob patterns are expanded at call time. Files of fs.FS based templates are
+// matched by their path inside the file system, and zip based templates by
+// the path of their archive.
+func (r Render) TemplatesForFile(path string) []string {
+	return r.registry().TemplatesForFile(path)
+}
+
+// Instance supply render string
+func (r Render) Instance(name string, data interface{}) render.Render {
+	return r.registry().Instance(name, data)
+}
+
+// InstanceCtx works like Instance but hands the request context to the
+// renderer hooks. gin's c.HTML calls Instance, so hooks see a nil context
+// there; use c.Render(code, r.InstanceCtx(c, name, data)) to provide it.
+func (r Render) InstanceCtx(c *gin.Context, name string, data interface{}) render.Render {
+	return r.registry().InstanceCtx(c, name, data)
+}
+
+// InstanceWith renders tmpl, e.g. a stub in a test, like Instance renders a
+// registered template, with the data hook, post processors, headers and
+// other settings of the renderer, but without registering it. The name of
+// tmpl stands in for the template name, e.g. for SetCachePolicy. The output
+// is never cached.
+func (r Render) InstanceWith(tmpl *template.Template, data interface{}) render.Render {
+	return r.registry().InstanceWith(tmpl, data)
+}
+
+// InstanceFuncs works like Instance but executes a clone of the template
+// with the functions of funcMap, e.g. helpers bound to the current user,
+// replacing those it was parsed with. The template is not parsed again:
+// functions the template calls must be known at registration, e.g. as stubs
+// in its function map, and only their implementation is replaced. The
+// output is never cached.
+func (r Render) InstanceFuncs(name string, funcMap template.FuncMap, data interface{}) render.Render {
+	return r.registry().InstanceFuncs(name, funcMap, data)
+}
+
+// InstanceTimeout works like Instance but aborts the render when executing
+// the template takes longer than d. The template is executed into a buffer,
+// so on timeout nothing but a 503 status is written and ErrRenderTimeout is
+// returned. The execution itself cannot be interrupted and finishes in the background.
+func (r Render) InstanceTimeout(name string, data interface{}, d time.Duration) render.Render {
+	return r.registry().InstanceTimeout(name, data, d)
+}
+
+// SetTimeout makes every render of the template name abort like
+// InstanceTimeout when executing it takes longer than d, including renders
+// created with Instance and so gin's c.HTML. A timeout given to
+// InstanceTimeout takes precedence. A d of zero removes the timeout.
+func (r Render) SetTimeout(name string, d time.Duration) {
+	r.registry().SetTimeout(name, d)
+}
+
+// ReloadAll rebuilds every template kept by the renderer from its sources,
+// e.g. after the template files were updated on disk. Templates added as a
+// *template.Template are kept as they are. If any template fails to build,
+// the error is returned and the renderer keeps serving the previous templates.
+// A dynamic renderer only keeps the templates pinned with Pin, so only those
+// are reloaded.
+func (r Render) ReloadAll() error {
+	reg := r.registry()
+	defer reg.mirrorTemplates(r)
+	return reg.ReloadAll()
+}
+
+// SetVersion sets the version of the templates, e.g. pushed to every
+// instance of a cluster through configuration. When the version differs from
+// the one the templates were built for, the next render reloads all templates
+// like ReloadAll before rendering. A failed reload is reported once by that
+// render and the previous templates stay in use until the version changes again.
+func (r Render) SetVersion(v string) {
+	r.registry().SetVersion(v)
+}
+
+// Version returns the version set with SetVersion.
+func (r Render) Version() string {
+	return r.registry().Version()
+}
+
+// StageAndSwap builds a new set of templates and replaces all templates of
+// the renderer with it only if every one of them is valid. build registers
+// the templates on a staging renderer of the same kind and options; the
+// staged templates are then checked with Validate. If build returns an
+// error, panics (as Add methods do on invalid templates) or validation
+// fails, the error is returned and the renderer is left untouched. Otherwise
+// the options and the per-template settings set on the staging renderer,
+// e.g. with SetCachePolicy, SetTimeout or SetCacheable, replace those of the
+// renderer along with the templates.
+func (r Render) StageAndSwap(build func(r Renderer) error) (err error) {
+	reg := r.registry()
+	defer reg.mirrorTemplates(r)
+	return reg.StageAndSwap(build)
+}
+
+// UpdateString replaces the body of the string template registered under
+// name, e.g. from a live template editor. The new body is parsed with the
+// function map, delimiters and options the template was registered with, and
+// only replaces the old one if it parses; otherwise the error is returned and
+// the previous template stays in use. Cached output of the template is
+// invalidated. Only templates added from a single string can be updated;
+// templates added from several strings, e.g. a page with its layout and
+// partials, are rejected with an error.
+func (r Render) UpdateString(name, body string) error {
+	reg := r.registry()
+	defer reg.mirrorTemplates(r)
+	return reg.UpdateString(name, body)
+}
+
+// InstanceSection renders only the named section (or any other defined
+// template) of the template registered under name.
+func (r Render) InstanceSection(name, section string, data interface{}) render.Render {
+	return r.registry().InstanceSection(name, section, data)
+}
+
+// InstanceBlocks renders the listed blocks (or any other defined templates)
+// of the template registered under name one after another into a single
+// response, e.g. several HTMX out-of-band swaps. Nothing is written unless
+// every block executed. The output is never cached.
+func (r Render) InstanceBlocks(name string, blocks []string, data interface{}) render.Render {
+	return r.registry().InstanceBlocks(name, blocks, data)
+}
+
+// InstanceOOB renders the given blocks (or any other defined templates) of
+// the template registered under name, each with its own data, as HTMX
+// out-of-band swaps: every block is wrapped in a
+// <div id="block" hx-swap-oob="true"> element named after it. Blocks are
+// rendered in name order and nothing is written unless every block executed.
+// The data hook of the renderer is not applied and the output is never cached.
+func (r Render) InstanceOOB(name string, blocks map[string]interface{}) render.Render {
+	return r.registry().InstanceOOB(name, blocks)
+}
+
+// CacheShell renders the layout template registered under name once with
+// data and keeps the output as the shell named shellName, e.g. a layout with
+// a navigation menu that is expensive to render but the same for every page.
+// The layout outputs ShellPlaceholder where page content goes, see
+// InstanceShell. Calling CacheShell again replaces the shell, e.g. after the
+// menu changed; reloading templates does not.
+func (r Render) CacheShell(shellName, name string, data interface{}) error {
+	return r.registry().CacheShell(shellName, name, data)
+}
+
+// InstanceShell renders the template registered under name with data inside
+// the shell cached with CacheShell, so only the page content is rendered per
+// request. The content is rendered like with Instance; the shell is written
+// before it executes, so a failing content template leaves a partial page.
+// It panics if no shell is cached under shellName.
+func (r Render) InstanceShell(shellName, name string, data interface{}) render.Render {
+	return r.registry().InstanceShell(shellName, name, data)
+}
+
+// Snapshot returns a view of the registered templates. The templates of a
+// dynamic renderer are built from their sources on every Build, those of a
+// static renderer are the ones built at the time of the snapshot.
+func (r Render) Snapshot() *Snapshot {
+	return r.registry().Snapshot()
+}
+
+// InstanceStream works like Instance but writes the output to the response
+// as the template executes instead of buffering it, feeding it to h as well.
+// Once the template executed successfully, the hex encoded sum of h is sent
+// as the HTTP trailer named trailer, e.g. to let clients verify the integrity
+// of long streamed pages. Output is not buffered, so a failing template leaves
+// a partial body without trailer, and WithPrettyHTML and WithPostProcessor do
+// not apply. h must not be shared between renders.
+func (r Render) InstanceStream(name string, data interface{}, trailer string, h hash.Hash) render.Render {
+	return r.registry().InstanceStream(name, data, trailer, h)
+}
+
+// UsedFuncs builds the template registered under name and returns the sorted
+// names of the functions its set calls, e.g. to split a large shared
+// function map into smaller ones. Builtin functions, such as "len" or
+// "printf", and the functions html/template and the renderer add are left out.
+func (r Render) UsedFuncs(name string) ([]string, error) {
+	return r.registry().UsedFuncs(name)
+}
+
+// Validate builds every registered template, in name order, and checks that
+// every {{template}} and {{block}} reference resolves to a template defined
+// in the same set, so broken includes are reported at startup instead of
+// when the page is first rendered, and that no template includes itself
+// unconditionally, see includeCycles. It returns all errors found, joined.
+func (r Render) Validate() error {
+	return r.registry().Validate()
+}
+
+// ValidateReport validates every template like Validate and returns whether
+// all of them are valid together with a readable report naming each failed
+// template and its errors, e.g. to print from TestMain or a
+// --check-templates flag.
+func (r Render) ValidateReport() (ok bool, report string) {
+	return r.registry().ValidateReport()
+}
+
+// ValidateIncludes builds every registered template and checks every
+// {{template}} and {{block}} reference against the templates defined by all
+// of them, unlike Validate which checks each set on its own, e.g. when the
+// partials are registered apart from the pages using them. It returns an
+// error naming the referencing template and the missing target of every
+// dangling reference, and the build errors, joined.
+func (r Render) ValidateIncludes() error {
+	return r.registry().ValidateIncludes()
+}
+
+// BatchRender works like Render.BatchRender.
+func (r DynamicRender) BatchRender(name string, items []interface{}, sink func(i int, r io.Reader) error) error {
+	return r.registry().BatchRender(name, items, sink)
+}
+
+// UseBundle works like Render.UseBundle.
+func (r DynamicRender) UseBundle(b FuncBundle) {
+	r.registry().UseBundle(b)
+}
+
+// HasChanged works like Render.HasChanged.
+func (r DynamicRender) HasChanged(name string) (bool, error) {
+	return r.registry().HasChanged(name)
+}
+
+// RenderHash works like Render.RenderHash.
+func (r DynamicRender) RenderHash(name string, data interface{}) (string, error) {
+	return r.registry().RenderHash(name, data)
+}
+
+// DiffRenders works like Render.DiffRenders.
+func (r DynamicRender) DiffRenders(name string, data interface{}, oldTemplate *template.Template) (bool, error) {
+	return r.registry().DiffRenders(name, data, oldTemplate)
+}
+
+// DryRun works like Render.DryRun.
+func (r DynamicRender) DryRun(name string, data interface{}) error {
+	return r.registry().DryRun(name, data)
+}
+
+// RenderEmail works like Render.RenderEmail.
+func (r DynamicRender) RenderEmail(name string, data interface{}) (string, error) {
+	return r.registry().RenderEmail(name, data)
+}
+
+// InstanceEmpty works like Render.InstanceEmpty.
+func (r DynamicRender) InstanceEmpty(headers map[string]string) render.Render {
+	return r.registry().InstanceEmpty(headers)
+}
+
+// RenderErrorPage works like Render.RenderErrorPage.
+func (r DynamicRender) RenderErrorPage(c *gin.Context, name string, err error) {
+	r.registry().RenderErrorPage(c, name, err)
+}
+
+// InstanceError works like Render.InstanceError.
+func (r DynamicRender) InstanceError(status int, data interface{}) render.Render {
+	return r.registry().InstanceError(status, data)
+}
+
+// InstanceETag works like Render.InstanceETag.
+func (r DynamicRender) InstanceETag(c *gin.Context, name string, data interface{}, etag string) render.Render {
+	return r.registry().InstanceETag(c, name, data, etag)
+}
+
+// RenderToWriter works like Render.RenderToWriter.
+func (r DynamicRender) RenderToWriter(w io.Writer, name string, data interface{}) error {
+	return r.registry().RenderToWriter(w, name, data)
+}
+
+// ExportStatic works like Render.ExportStatic.
+func (r DynamicRender) ExportStatic(dir string, jobs []ExportJob) error {
+	return r.registry().ExportStatic(dir, jobs)
+}
+
+// ExportSources works like Render.ExportSources.
+func (r DynamicRender) ExportSources() (map[string]SourceBundle, error) {
+	return r.registry().ExportSources()
+}
+
+// Meta works like Render.Meta.
+func (r DynamicRender) Meta(name string) (map[string]interface{}, error) {
+	return r.registry().Meta(name)
+}
+
+// SetCachePolicy works like Render.SetCachePolicy.
+func (r DynamicRender) SetCachePolicy(name, policy string) {
+	r.registry().SetCachePolicy(name, policy)
+}
+
+// InstanceHydrate works like Render.InstanceHydrate.
+func (r DynamicRender) InstanceHydrate(name string, data interface{}) render.Render {
+	return r.registry().InstanceHydrate(name, data)
+}
+
+// Names works like Render.Names.
+func (r DynamicRender) Names() []string {
+	return r.registry().Names()
+}
+
+// MustNames works like Render.MustNames.
+func (r DynamicRender) MustNames(expected ...string) {
+	r.registry().MustNames(expected...)
+}
+
+// AllDefinedTemplates works like Render.AllDefinedTemplates.
+func (r DynamicRender) AllDefinedTemplates() map[string][]string {
+	return r.registry().AllDefinedTemplates()
+}
+
+// ForEach works like Render.ForEach.
+func (r DynamicRender) ForEach(fn func(name string, tmpl *template.Template) bool) {
+	r.registry().ForEach(fn)
+}
+
+// Funcs works like Render.Funcs.
+func (r DynamicRender) Funcs(name string) template.FuncMap {
+	return r.registry().Funcs(name)
+}
+
+// DumpJSON works like Render.DumpJSON.
+func (r DynamicRender) DumpJSON() ([]byte, error) {
+	return r.registry().DumpJSON()
+}
+
+// InstanceList works like Render.InstanceList.
+func (r DynamicRender) InstanceList(outer, row string, items []interface{}, data interface{}) render.Render {
+	return r.registry().InstanceList(outer, row, items, data)
+}
+
+// InstanceLocale works like Render.InstanceLocale.
+func (r DynamicRender) InstanceLocale(name, locale string, data interface{}) render.Render {
+	return r.registry().InstanceLocale(name, locale, data)
+}
+
+// InstanceMerge works like Render.InstanceMerge.
+func (r DynamicRender) InstanceMerge(name string, base interface{}, extra map[string]interface{}) render.Render {
+	return r.registry().InstanceMerge(name, base, extra)
+}
+
+// InstanceMulti works like Render.InstanceMulti.
+func (r DynamicRender) InstanceMulti(name string, datas ...interface{}) render.Render {
+	return r.registry().InstanceMulti(name, datas...)
+}
+
+// SetCacheable works like Render.SetCacheable.
+func (r DynamicRender) SetCacheable(names ...string) {
+	r.registry().SetCacheable(names...)
+}
+
+// InvalidateOutput works like Render.InvalidateOutput.
+func (r DynamicRender) InvalidateOutput(name string) {
+	r.registry().InvalidateOutput(name)
+}
+
+// RecentErrors works like Render.RecentErrors.
+func (r DynamicRender) RecentErrors() []RenderError {
+	return r.registry().RecentErrors()
+}
+
+// Exists works like Render.Exists.
+func (r DynamicRender) Exists(name string) bool {
+	return r.registry().Exists(name)
+}
+
+// Build works like Render.Build.
+func (r DynamicRender) Build(name string) (*template.Template, error) {
+	return r.registry().Build(name)
+}
+
+// DefinedTemplates works like Render.DefinedTemplates.
+func (r DynamicRender) DefinedTemplates(name string) ([]string, error) {
+	return r.registry().DefinedTemplates(name)
+}
+
+// Merge works like Render.Merge.
+func (r DynamicRender) Merge(other Renderer) error {
+	reg := r.registry()
+	defer reg.mirrorBuilders(r)
+	return reg.Merge(other)
+}
+
+// SetCommonFiles works like Render.SetCommonFiles.
+func (r DynamicRender) SetCommonFiles(files ...string) {
+	r.registry().SetCommonFiles(files...)
+}
+
+// SetDebugFuncs works like Render.SetDebugFuncs.
+func (r DynamicRender) SetDebugFuncs(funcMap template.FuncMap) {
+	r.registry().SetDebugFuncs(funcMap)
+}
+
+// SetFlagProvider works like Render.SetFlagProvider.
+func (r DynamicRender) SetFlagProvider(fn func(name string, c *gin.Context) bool) {
+	r.registry().SetFlagProvider(fn)
+}
+
+// SetResolver works like Render.SetResolver.
+func (r DynamicRender) SetResolver(fn func(name string, c *gin.Context) string) {
+	r.registry().SetResolver(fn)
+}
+
+// SetSRIManifest works like Render.SetSRIManifest.
+func (r DynamicRender) SetSRIManifest(manifest map[string]string) {
+	r.registry().SetSRIManifest(manifest)
+}
+
+// TemplatesForFile works like Render.TemplatesForFile.
+func (r DynamicRender) TemplatesForFile(path string) []string {
+	return r.registry().TemplatesForFile(path)
+}
+
+// Instance works like Render.Instance.
+func (r DynamicRender) Instance(name string, data interface{}) render.Render {
+	return r.registry().Instance(name, data)
+}
+
+// InstanceCtx works like Render.InstanceCtx.
+func (r DynamicRender) InstanceCtx(c *gin.Context, name string, data interface{}) render.Render {
+	return r.registry().InstanceCtx(c, name, data)
+}
+
+// InstanceWith works like Render.InstanceWith.
+func (r DynamicRender) InstanceWith(tmpl *template.Template, data interface{}) render.Render {
+	return r.registry().InstanceWith(tmpl, data)
+}
+
+// InstanceFuncs works like Render.InstanceFuncs.
+func (r DynamicRender) InstanceFuncs(name string, funcMap template.FuncMap, data interface{}) render.Render {
+	return r.registry().InstanceFuncs(name, funcMap, data)
+}
+
+// InstanceTimeout works like Render.InstanceTimeout.
+func (r DynamicRender) InstanceTimeout(name string, data interface{}, d time.Duration) render.Render {
+	return r.registry().InstanceTimeout(name, data, d)
+}
+
+// SetTimeout works like Render.SetTimeout.
+func (r DynamicRender) SetTimeout(name string, d time.Duration) {
+	r.registry().SetTimeout(name, d)
+}
+
+// ReloadAll works like Render.ReloadAll.
+func (r DynamicRender) ReloadAll() error {
+	reg := r.registry()
+	defer reg.mirrorBuilders(r)
+	return reg.ReloadAll()
+}
+
+// SetVersion works like Render.SetVersion.
+func (r DynamicRender) SetVersion(v string) {
+	r.registry().SetVersion(v)
+}
+
+// Version works like Render.Version.
+func (r DynamicRender) Version() string {
+	return r.registry().Version()
+}
+
+// StageAndSwap works like Render.StageAndSwap.
+func (r DynamicRender) StageAndSwap(build func(r Renderer) error) (err error) {
+	reg := r.registry()
+	defer reg.mirrorBuilders(r)
+	return reg.StageAndSwap(build)
+}
+
+// UpdateString works like Render.UpdateString.
+func (r DynamicRender) UpdateString(name, body string) error {
+	reg := r.registry()
+	defer reg.mirrorBuilders(r)
+	return reg.UpdateString(name, body)
+}
+
+// InstanceSection works like Render.InstanceSection.
+func (r DynamicRender) InstanceSection(name, section string, data interface{}) render.Render {
+	return r.registry().InstanceSection(name, section, data)
+}
+
+// InstanceBlocks works like Render.InstanceBlocks.
+func (r DynamicRender) InstanceBlocks(name string, blocks []string, data interface{}) render.Render {
+	return r.registry().InstanceBlocks(name, blocks, data)
+}
+
+// InstanceOOB works like Render.InstanceOOB.
+func (r DynamicRender) InstanceOOB(name string, blocks map[string]interface{}) render.Render {
+	return r.registry().InstanceOOB(name, blocks)
+}
+
+// CacheShell works like Render.CacheShell.
+func (r DynamicRender) CacheShell(shellName, name string, data interface{}) error {
+	return r.registry().CacheShell(shellName, name, data)
+}
+
+// InstanceShell works like Render.InstanceShell.
+func (r DynamicRender) InstanceShell(shellName, name string, data interface{}) render.Render {
+	return r.registry().InstanceShell(shellName, name, data)
+}
+
+// Snapshot works like Render.Snapshot.
+func (r DynamicRender) Snapshot() *Snapshot {
+	return r.registry().Snapshot()
+}
+
+// InstanceStream works like Render.InstanceStream.
+func (r DynamicRender) InstanceStream(name string, data interface{}, trailer string, h hash.Hash) render.Render {
+	return r.registry().InstanceStream(name, data, trailer, h)
+}
+
+// UsedFuncs works like Render.UsedFuncs.
+func (r DynamicRender) UsedFuncs(name string) ([]string, error) {
+	return r.registry().UsedFuncs(name)
+}
+
+// Validate works like Render.Validate.
+func (r DynamicRender) Validate() error {
+	return r.registry().Validate()
+}
+
+// ValidateReport works like Render.ValidateReport.
+func (r DynamicRender) ValidateReport() (ok bool, report string) {
+	return r.registry().ValidateReport()
+}
+
+// ValidateIncludes works like Render.ValidateIncludes.
+func (r DynamicRender) ValidateIncludes() error {
+	return r.registry().ValidateIncludes()
+}
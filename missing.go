@@ -0,0 +1,89 @@
+package multitemplate
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/gin-gonic/gin"
+)
+
+// missingFunc is the function appended to the output actions of templates by
+// WithMissingPlaceholder.
+const missingFunc = "_multitemplate_missing"
+
+// markMissing appends missingFunc to every action of tmpl printing a field,
+// so a missing field renders the placeholder of WithMissingPlaceholder. It
+// does nothing in release mode or without a placeholder.
+func (tb templateBuilder) markMissing(tmpl executor) {
+	if tb.settings == nil || tb.settings.missingPlaceholder == nil || !gin.IsDebugging() {
+		return
+	}
+	placeholder := tb.settings.missingPlaceholder
+	fn := func(field string, v interface{}) interface{} {
+		if v == nil {
+			return placeholder(field)
+		}
+		return v
+	}
+
+	for _, tree := range templateTrees(tmpl) {
+		if tree == nil || tree.Root == nil {
+			continue
+		}
+		walkNodes(tree.Root, func(node parse.Node) {
+			if action, ok := node.(*parse.ActionNode); ok {
+				markMissingAction(tree, action)
+			}
+		})
+	}
+	switch t := tmpl.(type) {
+	case *template.Template:
+		t.Funcs(template.FuncMap{missingFunc: fn})
+	case *texttemplate.Template:
+		t.Funcs(texttemplate.FuncMap{missingFunc: fn})
+	}
+}
+
+// markMissingAction appends missingFunc to the pipeline of action if it
+// prints a field and was neither marked nor escaped before.
+func markMissingAction(tree *parse.Tree, action *parse.ActionNode) {
+	pipe := action.Pipe
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+		return
+	}
+	for _, cmd := range pipe.Cmds {
+		if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok &&
+			(ident.Ident == missingFunc || strings.HasPrefix(ident.Ident, "_html_template_")) {
+			return
+		}
+	}
+	last := pipe.Cmds[len(pipe.Cmds)-1]
+	if len(last.Args) != 1 {
+		return
+	}
+	var field []string
+	switch arg := last.Args[0].(type) {
+	case *parse.FieldNode:
+		field = arg.Ident
+	case *parse.ChainNode:
+		field = arg.Field
+	case *parse.VariableNode:
+		field = arg.Ident[1:]
+	}
+	if len(field) == 0 {
+		return
+	}
+
+	name := strings.Join(field, ".")
+	pipe.Cmds = append(pipe.Cmds, &parse.CommandNode{
+		NodeType: parse.NodeCommand,
+		Pos:      last.Pos,
+		Args: []parse.Node{
+			parse.NewIdentifier(missingFunc).SetTree(tree).SetPos(last.Pos),
+			&parse.StringNode{NodeType: parse.NodeString, Pos: last.Pos, Quoted: strconv.Quote(name), Text: name},
+		},
+	})
+}
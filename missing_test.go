@@ -0,0 +1,34 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMissingPlaceholder(t *testing.T) {
+	placeholder := WithMissingPlaceholder(func(field string) string { return "[missing:" + field + "]" })
+	data := gin.H{"name": "gin", "user": gin.H{}}
+
+	for _, r := range []testRenderer{New(placeholder), NewDynamic(placeholder)} {
+		r.AddFromString("index", `{{ .name }} {{ .title }} {{ .user.email }}{{ if .admin }}admin{{ end }}`)
+		r.AddTrustedFromString("text", `{{ .name }} {{ .title | printf "%v" }} {{ .user.email }}`)
+
+		w, err := renderResponse(r, "index", data)
+		assert.NoError(t, err)
+		assert.Equal(t, "gin [missing:title] [missing:user.email]", w.Body.String())
+
+		w, err = renderResponse(r, "text", data)
+		assert.NoError(t, err)
+		assert.Equal(t, "gin <nil> [missing:user.email]", w.Body.String())
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.DebugMode)
+	r := New(placeholder)
+	r.AddFromString("index", `{{ .name }} {{ .title }}`)
+	w, err := renderResponse(r, "index", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "gin ", w.Body.String())
+}
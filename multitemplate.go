@@ -5,11 +5,13 @@ import (
 	"html/template"
 	"io/fs"
 	"path/filepath"
+	texttemplate "text/template"
 
 	"github.com/gin-gonic/gin/render"
 )
 
-// Render type
+// Render type. Templates stored into the map directly are registered as if
+// added with Add when the map has grown on the next call of a method.
 type (
 	Render          map[string]*template.Template
 	TemplateOptions struct {
@@ -63,8 +65,8 @@ var (
 )
 
 // New instance
-func New() Render {
-	return make(Render)
+func New(opts ...RendererOption) Render {
+	return bind(make(Render), newRegistry(false, opts))
 }
 
 // Add new template
@@ -72,49 +74,138 @@ func (r Render) Add(name string, tmpl *template.Template) {
 	if tmpl == nil {
 		panic("template can not be nil")
 	}
+	r.register(name, &templateBuilder{
+		buildType:    templateType,
+		templateName: name,
+		tmpl:         tmpl,
+		options:      *NewTemplateOptions(),
+	})
+}
+
+// add builds the template described by builder and registers it under name
+func (r Render) add(name string, builder *templateBuilder) *template.Template {
+	builder.settings = &r.registry().opts
+	if err := builder.keep(); err != nil {
+		panic(err)
+	}
+	r.register(name, builder)
+	return builder.tmpl
+}
+
+func (r Render) register(name string, builder *templateBuilder) {
 	if len(name) == 0 {
 		panic("template name cannot be empty")
 	}
-	if _, ok := r[name]; ok {
+
+	reg := r.registry()
+	reg.mu.Lock()
+	if _, ok := reg.builders[name]; ok {
+		reg.mu.Unlock()
 		panic(fmt.Sprintf("template %s already exists", name))
 	}
-	r[name] = tmpl
+	builder.settings = &reg.opts
+	reg.builders[name] = builder
+	reg.gen.Add(1)
+	reg.mu.Unlock()
+	reg.mirrorTemplates(r)
 }
 
 // AddFromFiles supply add template from files
 func (r Render) AddFromFiles(name string, files ...string) *template.Template {
-	tmpl := template.Must(template.ParseFiles(files...))
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:    filesTemplateType,
+		templateName: name,
+		files:        files,
+		options:      *NewTemplateOptions(),
+	})
+}
+
+// AddIf supply add template from files only if cond is true, e.g. for views
+// behind a feature flag. Otherwise nothing is registered, so Exists reports
+// false and Instance handles name like any unknown template, and nil is returned.
+// Templates registered in other ways can be gated the same way with an if
+// around the Add call, as the other handlers only need Exists.
+func (r Render) AddIf(cond bool, name string, files ...string) *template.Template {
+	if !cond {
+		return nil
+	}
+	return r.AddFromFiles(name, files...)
 }
 
 // AddFromGlob supply add template from global path
 func (r Render) AddFromGlob(name, glob string) *template.Template {
-	tmpl := template.Must(template.ParseGlob(glob))
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:    globTemplateType,
+		templateName: name,
+		glob:         glob,
+		options:      *NewTemplateOptions(),
+	})
 }
 
 // AddFromFS supply add template from fs.FS (e.g. embed.FS)
 func (r Render) AddFromFS(name string, fsys fs.FS, files ...string) *template.Template {
-	tmpl := template.Must(template.ParseFS(fsys, files...))
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:    fsTemplateType,
+		templateName: name,
+		fsys:         fsys,
+		files:        files,
+		options:      *NewTemplateOptions(),
+	})
 }
 
 // AddFromFSFuncs supply add template from fs.FS (e.g. embed.FS) with callback func
 func (r Render) AddFromFSFuncs(name string, funcMap template.FuncMap, fsys fs.FS, files ...string) *template.Template {
-	tname := filepath.Base(files[0])
-	tmpl := template.Must(template.New(tname).Funcs(funcMap).ParseFS(fsys, files...))
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:    fsFuncTemplateType,
+		templateName: filepath.Base(files[0]),
+		funcMap:      funcMap,
+		fsys:         fsys,
+		files:        files,
+		options:      *NewTemplateOptions(),
+	})
+}
+
+// AddFromFSFuncsGlob supply add template from the files of fs.FS (e.g. embed.FS)
+// matching pattern, with callback func
+func (r Render) AddFromFSFuncsGlob(
+	name string,
+	funcMap template.FuncMap,
+	fsys fs.FS,
+	pattern string,
+) *template.Template {
+	return r.AddFromFSFuncs(name, funcMap, fsys, pattern)
 }
 
 // AddFromString supply add template from strings
 func (r Render) AddFromString(name, templateString string) *template.Template {
-	tmpl := template.Must(template.New(name).Parse(templateString))
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(),
+	})
+}
+
+// AddFromStringDelims supply add template from strings parsed with the
+// left and right delimiters, overriding the default ones for this template only
+func (r Render) AddFromStringDelims(name, left, right, templateString string) *template.Template {
+	return r.add(name, &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(Delims(left, right)),
+	})
+}
+
+// AddFromStringNamed supply add template from strings, registered under key and
+// parsed as the template templateName
+func (r Render) AddFromStringNamed(key, templateName, templateString string) *template.Template {
+	return r.add(key, &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   templateName,
+		templateString: templateString,
+		options:        *NewTemplateOptions(),
+	})
 }
 
 // AddFromStringsFuncs supply add template from strings
@@ -123,14 +214,7 @@ func (r Render) AddFromStringsFuncs(
 	funcMap template.FuncMap,
 	templateStrings ...string,
 ) *template.Template {
-	tmpl := template.New(name).Funcs(funcMap)
-
-	for _, ts := range templateStrings {
-		tmpl = template.Must(tmpl.Parse(ts))
-	}
-
-	r.Add(name, tmpl)
-	return tmpl
+	return r.AddFromStringsFuncsWithOptions(name, funcMap, *NewTemplateOptions(), templateStrings...)
 }
 
 // AddFromStringsFuncsWithOptions supply add template from strings with options
@@ -140,26 +224,18 @@ func (r Render) AddFromStringsFuncsWithOptions(
 	options TemplateOptions,
 	templateStrings ...string,
 ) *template.Template {
-	tmpl := template.New(name).
-		Delims(options.LeftDelimiter, options.RightDelimiter).
-		Funcs(funcMap)
-
-	for _, ts := range templateStrings {
-		tmpl = template.Must(
-			tmpl.Parse(ts),
-		).Delims(options.LeftDelimiter, options.RightDelimiter)
-	}
-
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:       stringFuncTemplateType,
+		templateName:    name,
+		funcMap:         funcMap,
+		templateStrings: templateStrings,
+		options:         options,
+	})
 }
 
 // AddFromFilesFuncs supply add template from file callback func
 func (r Render) AddFromFilesFuncs(name string, funcMap template.FuncMap, files ...string) *template.Template {
-	tname := filepath.Base(files[0])
-	tmpl := template.Must(template.New(tname).Funcs(funcMap).ParseFiles(files...))
-	r.Add(name, tmpl)
-	return tmpl
+	return r.AddFromFilesFuncsWithOptions(name, funcMap, *NewTemplateOptions(), files...)
 }
 
 // AddFromFilesFuncsWithOptions supply add template from file callback func with options
@@ -169,21 +245,113 @@ func (r Render) AddFromFilesFuncsWithOptions(
 	options TemplateOptions,
 	files ...string,
 ) *template.Template {
-	tname := filepath.Base(files[0])
-	tmpl := template.Must(
-		template.New(tname).
-			Delims(options.LeftDelimiter, options.RightDelimiter).
-			Funcs(funcMap).
-			ParseFiles(files...),
-	)
-	r.Add(name, tmpl)
-	return tmpl
+	return r.add(name, &templateBuilder{
+		buildType:    filesFuncTemplateType,
+		templateName: filepath.Base(files[0]),
+		funcMap:      funcMap,
+		files:        files,
+		options:      options,
+	})
+}
+
+// AddFromFileSections supply add template from a file split into sections by markers
+func (r Render) AddFromFileSections(name, file string) *template.Template {
+	return r.add(name, &templateBuilder{
+		buildType:    sectionsTemplateType,
+		templateName: name,
+		files:        []string{file},
+		options:      *NewTemplateOptions(),
+	})
+}
+
+// AddAllFromFS supply add every file below root in fs.FS (e.g. embed.FS) as its own template,
+// registered under its path relative to root
+func (r Render) AddAllFromFS(fsys fs.FS, root string) {
+	files, err := walkFS(fsys, root)
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range sortedNames(files) {
+		r.add(name, &templateBuilder{
+			buildType:    fsTemplateType,
+			templateName: name,
+			fsys:         fsys,
+			files:        []string{files[name]},
+			options:      *NewTemplateOptions(),
+			literal:      true,
+		})
+	}
+}
+
+// AddTrustedFromString supply add trusted template from strings. Trusted
+// templates are parsed with text/template instead of html/template: their
+// output is sent as text/html WITHOUT any contextual escaping, so data
+// rendered by them must already be safe HTML, e.g. sanitized content from a
+// trusted CMS. Rendering user input with a trusted template exposes the page
+// to cross-site scripting (XSS).
+func (r Render) AddTrustedFromString(name, templateString string) *texttemplate.Template {
+	builder := &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(),
+		text:           true,
+	}
+	r.add(name, builder)
+	return builder.textTmpl
+}
+
+// AddTrustedFromFiles supply add trusted template from files, see AddTrustedFromString
+func (r Render) AddTrustedFromFiles(name string, files ...string) *texttemplate.Template {
+	builder := &templateBuilder{
+		buildType:    filesTemplateType,
+		templateName: name,
+		files:        files,
+		options:      *NewTemplateOptions(),
+		text:         true,
+	}
+	r.add(name, builder)
+	return builder.textTmpl
+}
+
+// AddTrustedFromFS supply add trusted template from fs.FS (e.g. embed.FS), see AddTrustedFromString
+func (r Render) AddTrustedFromFS(name string, fsys fs.FS, files ...string) *texttemplate.Template {
+	builder := &templateBuilder{
+		buildType:    fsTemplateType,
+		templateName: name,
+		fsys:         fsys,
+		files:        files,
+		options:      *NewTemplateOptions(),
+		text:         true,
+	}
+	r.add(name, builder)
+	return builder.textTmpl
+}
+
+// AddTextFromString supply add plain text template from strings, rendered as text/plain
+func (r Render) AddTextFromString(name, templateString string) *texttemplate.Template {
+	builder := &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   name,
+		templateString: templateString,
+		options:        *NewTemplateOptions(),
+		text:           true,
+		contentType:    plainContentType,
+	}
+	r.add(name, builder)
+	return builder.textTmpl
 }
 
-// Instance supply render string
-func (r Render) Instance(name string, data interface{}) render.Render {
-	return render.HTML{
-		Template: r[name],
-		Data:     data,
+// AddTextFromFiles supply add plain text template from files, rendered as text/plain
+func (r Render) AddTextFromFiles(name string, files ...string) *texttemplate.Template {
+	builder := &templateBuilder{
+		buildType:    filesTemplateType,
+		templateName: name,
+		files:        files,
+		options:      *NewTemplateOptions(),
+		text:         true,
+		contentType:  plainContentType,
 	}
+	r.add(name, builder)
+	return builder.textTmpl
 }
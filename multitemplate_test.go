@@ -2,13 +2,20 @@ package multitemplate
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"html/template"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,6 +26,18 @@ func performRequest(r http.Handler) *httptest.ResponseRecorder {
 	return w
 }
 
+func renderResponse(r render.HTMLRender, name string, data interface{}) (w *httptest.ResponseRecorder, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("render template %s: %v", name, p)
+		}
+	}()
+
+	w = httptest.NewRecorder()
+	err = r.Instance(name, data).Render(w)
+	return w, err
+}
+
 func createFromFile() Render {
 	r := New()
 	r.AddFromFiles("index", "tests/base.html", "tests/article.html")
@@ -133,6 +152,49 @@ func TestAddFromString(t *testing.T) {
 	assert.Equal(t, "Welcome to index template", w.Body.String())
 }
 
+func TestAddFromStringDelims(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromString("index", "Welcome to {{ .name }} template")
+		r.AddFromStringDelims("vue", "[[", "]]", "<p>{{ message }}</p> [[ .name ]]")
+
+		w, err := renderResponse(r, "vue", gin.H{"name": "vue"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>{{ message }}</p> vue", w.Body.String())
+
+		w, err = renderResponse(r, "index", gin.H{"name": "index"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to index template", w.Body.String())
+	}
+}
+
+func TestAddFromStringNamed(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		tmpl := r.AddFromStringNamed("/welcome", "page", `{{define "page"}}Welcome to {{ .name }} page{{end}}`)
+		assert.Equal(t, "page", tmpl.Name())
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "/welcome", gin.H{"name": "index"})
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "Welcome to index page", w.Body.String())
+	}
+}
+
+func TestAddIf(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		assert.NotNil(t, r.AddIf(true, "on", "tests/base.html", "tests/article.html"))
+		assert.Nil(t, r.AddIf(false, "off", "tests/base.html", "tests/article.html"))
+
+		assert.True(t, r.Exists("on"))
+		assert.False(t, r.Exists("off"))
+		assert.Panics(t, func() { r.Instance("off", nil) })
+	}
+}
+
 func TestAddFromStringsFruncs(t *testing.T) {
 	router := gin.New()
 	router.HTMLRender = createFromStringsWithFuncs()
@@ -168,3 +230,513 @@ func TestDuplicateTemplate(t *testing.T) {
 		r.AddFromString("index", "Welcome to {{ .name }} template")
 	})
 }
+
+func TestMerge(t *testing.T) {
+	core := New()
+	core.AddFromString("index", "Welcome to {{ .name }} template")
+	feature := New()
+	feature.AddFromString("feature", "Feature {{ .name }}")
+
+	assert.NoError(t, core.Merge(feature))
+	assert.Contains(t, core, "index")
+	assert.Contains(t, core, "feature")
+
+	dup := New()
+	dup.AddFromString("index", "duplicate")
+	dup.AddFromString("other", "other")
+	assert.Error(t, core.Merge(dup))
+	assert.NotContains(t, core, "other")
+}
+
+func TestMergeSettings(t *testing.T) {
+	core := New()
+	core.AddErrorPage(404, "index", homeFiles["en"])
+	feature := New()
+	feature.AddLocalized("home", homeFiles)
+	feature.AddFromString("feature", "Feature")
+	feature.SetCachePolicy("feature", "max-age=60")
+	feature.SetTimeout("feature", time.Second)
+	feature.SetCacheable("feature")
+
+	assert.NoError(t, core.Merge(feature))
+	reg := core.registry()
+	assert.Equal(t, "max-age=60", reg.cachePolicies["feature"])
+	assert.Equal(t, time.Second, reg.timeouts["feature"])
+	assert.True(t, reg.cacheable["feature"])
+	w := httptest.NewRecorder()
+	assert.NoError(t, core.InstanceLocale("home", "de", gin.H{"name": "Ann"}).Render(w))
+	assert.Equal(t, "Hallo Ann", w.Body.String())
+
+	pages := New()
+	pages.AddErrorPage(404, "notfound", homeFiles["de"])
+	assert.EqualError(t, core.Merge(pages), "error page for status 404 already exists")
+	assert.NotContains(t, core, "notfound")
+}
+
+func TestMergeConcurrent(t *testing.T) {
+	a, b := New(), New()
+	a.AddFromString("a", "a")
+	b.AddFromString("b", "b")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = a.Merge(b)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = b.Merge(a)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRenderMap(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+	assert.Contains(t, r, "index")
+
+	literal := Render{"index": template.Must(template.New("index").Parse("Hello {{ .name }}"))}
+	literal["other"] = template.Must(template.New("other").Parse("Other {{ .name }}"))
+	for _, name := range []string{"index", "other"} {
+		w, err := renderResponse(literal, name, gin.H{"name": "gin"})
+		assert.NoError(t, err)
+		assert.Contains(t, w.Body.String(), "gin")
+	}
+	assert.True(t, literal.Exists("other"))
+}
+
+func TestWithRecover(t *testing.T) {
+	r := New(WithRecover(func(name string, _ interface{}) render.Render {
+		return render.String{Format: "fallback for %s", Data: []interface{}{name}}
+	}))
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "missing", nil)
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "fallback for missing", w.Body.String())
+}
+
+func TestWithDuplicateDefineCheck(t *testing.T) {
+	assert.NotPanics(t, func() {
+		r := New()
+		r.AddFromFiles("index", "tests/welcome.html", "tests/duplicate/first.html", "tests/duplicate/second.html")
+	})
+
+	r := New(WithDuplicateDefineCheck())
+	assert.NotPanics(t, func() {
+		r.AddFromFilesFuncs("index", template.FuncMap{}, "tests/welcome.html", "tests/content.html")
+	})
+	assert.PanicsWithError(t,
+		`template welcome.html defines duplicate templates: "content" in tests/duplicate/first.html, tests/duplicate/second.html`,
+		func() {
+			r.AddFromFilesFuncs("dup", template.FuncMap{},
+				"tests/welcome.html", "tests/duplicate/first.html", "tests/duplicate/second.html")
+		})
+	assert.Panics(t, func() {
+		r.AddTrustedFromFiles("trusted", "tests/welcome.html", "tests/duplicate/first.html", "tests/duplicate/second.html")
+	})
+}
+
+func TestSetFlagProvider(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.SetFlagProvider(func(name string, c *gin.Context) bool {
+			return c != nil && name == "beta" && c.Query("beta") == "1"
+		})
+		r.AddFromString("index", `{{ if flag "beta" }}new{{ else }}old{{ end }} checkout`)
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.Render(200, r.InstanceCtx(c, "index", nil))
+		})
+		router.GET("/plain", func(c *gin.Context) {
+			c.HTML(200, "index", nil)
+		})
+
+		for _, tt := range []struct{ path, body string }{
+			{"/?beta=1", "new checkout"},
+			{"/", "old checkout"},
+			{"/plain?beta=1", "old checkout"},
+		} {
+			req, _ := http.NewRequestWithContext(context.Background(), "GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.body, w.Body.String(), tt.path)
+		}
+	}
+}
+
+func TestSetFlagProviderAfterRender(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	w, err := renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+
+	r.SetFlagProvider(func(name string, _ *gin.Context) bool { return name == "beta" })
+	r.AddFromString("checkout", `{{ if flag "beta" }}new{{ else }}old{{ end }} checkout`)
+	w, err = renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+	w, err = renderResponse(r, "checkout", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "new checkout", w.Body.String())
+}
+
+func TestWithContextFuncs(t *testing.T) {
+	r := New(WithContextFuncs(func(c *gin.Context) template.FuncMap {
+		return template.FuncMap{
+			"path": func() string {
+				if c == nil {
+					return "unknown"
+				}
+				c.Header("X-Rendered", "true")
+				return c.Request.URL.Path
+			},
+		}
+	}))
+	r.AddFromString("index", "Path {{ path }}")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceCtx(c, "index", nil))
+	})
+	router.GET("/plain", func(c *gin.Context) {
+		c.HTML(200, "index", nil)
+	})
+
+	for i := 0; i < 2; i++ {
+		w := performRequest(router)
+		assert.Equal(t, "Path /", w.Body.String())
+		assert.Equal(t, "true", w.Header().Get("X-Rendered"))
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/plain", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "Path unknown", w.Body.String())
+}
+
+func TestWithLenientFuncs(t *testing.T) {
+	funcMap := template.FuncMap{
+		"widget": func(name string) (string, error) {
+			if name == "broken" {
+				return "", errors.New("widget unavailable")
+			}
+			return "[" + name + "]", nil
+		},
+	}
+
+	r := New(WithLenientFuncs())
+	r.AddFromStringsFuncs("dashboard", funcMap, `{{ widget "cpu" }}{{ widget "broken" }}{{ widget "disk" }}`)
+	w, err := renderResponse(r, "dashboard", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "[cpu][disk]", w.Body.String())
+
+	strict := New()
+	strict.AddFromStringsFuncs("dashboard", funcMap, `{{ widget "cpu" }}{{ widget "broken" }}`)
+	_, err = renderResponse(strict, "dashboard", nil)
+	assert.ErrorContains(t, err, "widget unavailable")
+}
+
+func TestSetSRIManifest(t *testing.T) {
+	manifest := map[string]string{"main.js": "sha384-abc"}
+	r := New()
+	r.SetSRIManifest(manifest)
+	manifest["main.js"] = "changed"
+	r.AddFromString("index", `<script src="/main.js" integrity="{{ sri "main.js" }}"></script>`)
+	r.AddFromString("missing", `<script integrity="{{ sri "app.js" }}"></script>`)
+
+	w, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `<script src="/main.js" integrity="sha384-abc"></script>`, w.Body.String())
+
+	_, err = renderResponse(r, "missing", nil)
+	assert.ErrorContains(t, err, `no SRI hash for asset "app.js"`)
+}
+
+func TestSetResolver(t *testing.T) {
+	r := New()
+	r.AddFromString("home", "desktop home")
+	r.AddFromString("home.mobile", "mobile home")
+	r.SetResolver(func(name string, c *gin.Context) string {
+		if c != nil && c.GetHeader("X-Device") == "mobile" {
+			return name + ".mobile"
+		}
+		return ""
+	})
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceCtx(c, "home", nil))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, "desktop home", w.Body.String())
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/", nil)
+	req.Header.Set("X-Device", "mobile")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "mobile home", w.Body.String())
+
+	r.SetCachePolicy("home.mobile", "private, max-age=60")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+
+	r.SetResolver(nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, "desktop home", w.Body.String())
+}
+
+func TestWithCSPNonce(t *testing.T) {
+	r := New(WithCSPNonce(func(c *gin.Context) string {
+		return c.GetString("nonce")
+	}))
+	r.AddFromString("index", `<script nonce="{{nonce}}">run()</script>`)
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Set("nonce", "r4nd0m")
+		c.Render(200, r.InstanceCtx(c, "index", nil))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, `<script nonce="r4nd0m">run()</script>`, w.Body.String())
+
+	router = gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.HTML(200, "index", nil)
+	})
+	w = performRequest(router)
+	assert.Equal(t, `<script nonce="">run()</script>`, w.Body.String())
+}
+
+func TestWithCSP(t *testing.T) {
+	r := New(WithCSP("script-src 'nonce-{nonce}'"))
+	r.AddFromString("index", `<script nonce="{{nonce}}">run()</script>`)
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceCtx(c, "index", nil))
+	})
+
+	first := performRequest(router)
+	nonce := strings.TrimSuffix(strings.TrimPrefix(first.Body.String(), `<script nonce="`), `">run()</script>`)
+	assert.Len(t, nonce, 22)
+	assert.Equal(t, "script-src 'nonce-"+nonce+"'", first.Header().Get("Content-Security-Policy"))
+
+	second := performRequest(router)
+	assert.NotEqual(t, first.Body.String(), second.Body.String())
+}
+
+func TestAddFromFileSections(t *testing.T) {
+	r := New()
+	r.AddFromFileSections("docs", "tests/sections.html")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceSection("docs", "intro", gin.H{"title": "Docs"}))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "\n<p>Intro for Docs</p>\n", w.Body.String())
+
+	w, err := renderResponse(r, "docs", gin.H{"title": "Docs"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<h1>Docs</h1>\n\n<p>Intro for Docs</p>\n\n\n<p>Usage</p>\n\n", w.Body.String())
+}
+
+func TestInstanceBlocks(t *testing.T) {
+	r := New()
+	r.AddFromFileSections("docs", "tests/sections.html")
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceBlocks("docs", []string{"usage", "intro"}, gin.H{"title": "Docs"}))
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		c.Render(200, r.InstanceBlocks("docs", []string{"intro", "missing"}, gin.H{"title": "Docs"}))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "\n<p>Usage</p>\n\n<p>Intro for Docs</p>\n", w.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Empty(t, w.Body.String())
+
+	assert.Panics(t, func() { r.InstanceBlocks("docs", nil, nil) })
+}
+
+func TestInstanceOOB(t *testing.T) {
+	r := New()
+	r.AddFromString("fragments", `{{define "count"}}{{ . }} items{{end}}{{define "toast"}}Saved {{ .name }}{{end}}`)
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceOOB("fragments", map[string]interface{}{
+			"toast": gin.H{"name": "<draft>"},
+			"count": 3,
+		}))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, `<div id="count" hx-swap-oob="true">3 items</div>`+
+		`<div id="toast" hx-swap-oob="true">Saved &lt;draft&gt;</div>`, w.Body.String())
+	assert.Panics(t, func() { r.InstanceOOB("fragments", nil) })
+}
+
+func TestAddFromGlobNoMatches(t *testing.T) {
+	wd, _ := os.Getwd()
+	assert.PanicsWithError(t,
+		`glob pattern "tests/missing/*" matches no files (working directory `+wd+`)`,
+		func() {
+			New().AddFromGlob("index", "tests/missing/*")
+		})
+}
+
+func TestDefinedTemplates(t *testing.T) {
+	r := createFromFile()
+
+	names, err := r.DefinedTemplates("index")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"article.html", "base.html"}, names)
+
+	_, err = r.DefinedTemplates("missing")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
+
+func TestWithBaseDir(t *testing.T) {
+	files := New(WithBaseDir("tests/basedir"))
+	files.AddFromFiles("page", "tests/basedir/page.html", "tests/basedir/partials/sidebar.html")
+
+	embedded := New(WithBaseDir("tests/basedir"))
+	embedded.AddFromFS("page", os.DirFS("."), "tests/basedir/page.html", "tests/basedir/partials/*.html")
+
+	for _, r := range []Render{files, embedded} {
+		names, err := r.DefinedTemplates("page")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"page.html", "partials/sidebar.html"}, names)
+
+		w, err := renderResponse(r, "page", gin.H{"title": "Sidebar"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<main><aside>Sidebar</aside></main>\n", w.Body.String())
+	}
+}
+
+func TestBuild(t *testing.T) {
+	r := New(WithMaxDepth(3))
+	tmpl := r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	built, err := r.Build("index")
+	assert.NoError(t, err)
+	assert.NotSame(t, tmpl, built)
+	assert.NoError(t, built.Execute(io.Discard, gin.H{"name": "built"}))
+
+	w, err := renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+}
+
+func TestWithSourceTransform(t *testing.T) {
+	transform := func(name, src string) (string, error) {
+		if strings.Contains(src, "@fail") {
+			return "", errors.New("cannot transform " + name)
+		}
+		return strings.ReplaceAll(src, "@title", "{{ .title }}"), nil
+	}
+
+	r := New(WithSourceTransform(transform))
+	r.AddFromString("index", "<h1>@title</h1>")
+	r.AddFromFiles("base", "tests/base.html", "tests/article.html")
+
+	w, err := renderResponse(r, "index", gin.H{"title": "Hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<h1>Hello</h1>", w.Body.String())
+
+	w, err = renderResponse(r, "base", gin.H{"title": "Hello"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Hello</p>\nHi, this is article template\n", w.Body.String())
+
+	assert.PanicsWithError(t, "cannot transform broken", func() {
+		r.AddFromString("broken", "@fail")
+	})
+}
+
+func TestInstanceWith(t *testing.T) {
+	r := New(
+		WithDataHook(func(_ *gin.Context, _ string, data interface{}) interface{} {
+			return gin.H{"name": data, "version": "v1"}
+		}),
+		WithPostProcessor(func(name string, out []byte) ([]byte, error) {
+			return append(out, "!"...), nil
+		}),
+	)
+	r.SetCachePolicy("stub", "public, max-age=60")
+
+	stub := template.Must(template.New("stub").Parse(`stub {{ .name }} {{ .version }}`))
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceWith(stub, "gin"))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "stub gin v1!", w.Body.String())
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+	assert.False(t, r.Exists("stub"))
+	assert.Panics(t, func() { r.InstanceWith(nil, nil) })
+}
+
+func TestInstanceFuncs(t *testing.T) {
+	stubs := template.FuncMap{"user": func() string { return "guest" }}
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromStringsFuncs("index", stubs, `Hello {{ user }}`)
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "index", nil)
+		})
+		router.GET("/user", func(c *gin.Context) {
+			name := c.Query("name")
+			c.Render(200, r.InstanceFuncs("index", template.FuncMap{"user": func() string { return name }}, nil))
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, "Hello guest", w.Body.String())
+
+		for _, name := range []string{"gin", "gonic"} {
+			w = httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/user?name="+name, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, 200, w.Code)
+			assert.Equal(t, "Hello "+name, w.Body.String())
+		}
+
+		w = performRequest(router)
+		assert.Equal(t, "Hello guest", w.Body.String())
+	}
+}
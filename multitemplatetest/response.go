@@ -0,0 +1,27 @@
+// Package multitemplatetest provides helpers to test templates of a
+// multitemplate renderer without running a gin engine.
+package multitemplatetest
+
+import (
+	"fmt"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// RenderResponse renders the named template of r into a response recorder,
+// which makes it easy to assert on status, headers and body in tests.
+// A panic raised while looking up or building the template is returned as an error.
+func RenderResponse(r render.HTMLRender, name string, data interface{}) (w *httptest.ResponseRecorder, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("render template %s: %v", name, p)
+		}
+	}()
+
+	w = httptest.NewRecorder()
+	if err := r.Instance(name, data).Render(w); err != nil {
+		return w, err
+	}
+	return w, nil
+}
@@ -0,0 +1,23 @@
+package multitemplatetest
+
+import (
+	"testing"
+
+	"github.com/gin-contrib/multitemplate"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderResponse(t *testing.T) {
+	r := multitemplate.NewRenderer()
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	w, err := RenderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+
+	_, err = RenderResponse(r, "missing", nil)
+	assert.Error(t, err)
+}
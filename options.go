@@ -0,0 +1,523 @@
+package multitemplate
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// RendererOption configures a renderer created by New, NewDynamic or NewRenderer.
+type RendererOption func(*rendererOptions)
+
+type rendererOptions struct {
+	// build settings
+	baseDir      string
+	commonFiles  []string
+	checkDefines bool
+	checkFuncs   bool
+	allowedFuncs map[string]bool
+	maxDepth     int
+	debugFuncs   template.FuncMap
+	bundleFuncs  template.FuncMap
+	bundles      map[string]bool
+	contextFuncs func(c *gin.Context) template.FuncMap
+	flagProvider func(name string, c *gin.Context) bool
+	cspNonce     func(c *gin.Context) string
+	cspPolicy    string
+
+	// render settings
+	recoverFunc   func(name string, r interface{}) render.Render
+	panicTemplate string
+	dataHook      func(c *gin.Context, name string, data interface{}) interface{}
+	resolver      func(name string, c *gin.Context) string
+	requestData   func(c *gin.Context) map[string]interface{}
+	lenientFuncs  bool
+	sriManifest   map[string]string
+	// missingPlaceholder renders missing fields in debug mode, see WithMissingPlaceholder.
+	missingPlaceholder func(field string) string
+	frontMatter        bool
+	missingAsEmpty     bool
+	missingStatus      int
+	emptyStatus        int
+	prettyHTML         bool
+	autoMinify         bool
+	serverTiming       bool
+	postProcessors     []func(name string, out []byte) ([]byte, error)
+	noStore            bool
+	cachePolicy        string
+	contentType        string
+	writeBufferSize    int
+	writerWrapper      func(w io.Writer) io.Writer
+	outputCacheSize    int
+	checksumReload     bool
+	defaultLocale      string
+	sourceTransform    func(name, src string) (string, error)
+}
+
+// funcs returns the functions every template is parsed with, in addition to
+// the function map given at registration.
+func (o *rendererOptions) funcs() template.FuncMap {
+	funcMap := template.FuncMap{}
+	for name, fn := range o.bundleFuncs {
+		funcMap[name] = fn
+	}
+	for name, fn := range o.debugFuncs {
+		if gin.IsDebugging() {
+			funcMap[name] = fn
+		} else {
+			funcMap[name] = noopFunc(fn)
+		}
+	}
+	if o.sriManifest != nil {
+		manifest := o.sriManifest
+		funcMap["sri"] = func(asset string) (string, error) {
+			integrity, ok := manifest[asset]
+			if !ok {
+				return "", fmt.Errorf("no SRI hash for asset %q", asset)
+			}
+			return integrity, nil
+		}
+	}
+	for name, fn := range o.requestFuncs(nil) {
+		funcMap[name] = fn
+	}
+	return funcMap
+}
+
+// requestFuncs returns the functions bound to the request c, or nil if the
+// renderer has none.
+func (o *rendererOptions) requestFuncs(c *gin.Context) template.FuncMap {
+	if o.contextFuncs == nil && o.cspNonce == nil && o.flagProvider == nil {
+		return nil
+	}
+
+	funcMap := template.FuncMap{}
+	if o.contextFuncs != nil {
+		for name, fn := range o.contextFuncs(c) {
+			funcMap[name] = o.lenient(name, fn)
+		}
+	}
+	if o.flagProvider != nil {
+		provider := o.flagProvider
+		funcMap["flag"] = func(name string) bool {
+			return provider(name, c)
+		}
+	}
+	if o.cspNonce != nil {
+		nonce := o.cspNonce
+		funcMap["nonce"] = func() string {
+			if c == nil {
+				return ""
+			}
+			return nonce(c)
+		}
+	}
+	return funcMap
+}
+
+// noopFunc returns a function with the signature of fn that does nothing and
+// returns zero values, so templates calling it still parse and execute.
+func noopFunc(fn interface{}) interface{} {
+	typ := reflect.TypeOf(fn)
+	if typ == nil || typ.Kind() != reflect.Func {
+		return fn
+	}
+	return reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+		results := make([]reflect.Value, typ.NumOut())
+		for i := range results {
+			results[i] = reflect.Zero(typ.Out(i))
+		}
+		return results
+	}).Interface()
+}
+
+// lenient returns fn wrapped to log and swallow the errors it returns when
+// WithLenientFuncs is set, and fn unchanged otherwise.
+func (o *rendererOptions) lenient(name string, fn interface{}) interface{} {
+	if !o.lenientFuncs {
+		return fn
+	}
+	typ := reflect.TypeOf(fn)
+	if typ == nil || typ.Kind() != reflect.Func || typ.NumOut() == 0 ||
+		typ.Out(typ.NumOut()-1) != reflect.TypeOf((*error)(nil)).Elem() {
+		return fn
+	}
+	v := reflect.ValueOf(fn)
+	return reflect.MakeFunc(typ, func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if typ.IsVariadic() {
+			results = v.CallSlice(args)
+		} else {
+			results = v.Call(args)
+		}
+		if err := results[len(results)-1]; !err.IsNil() {
+			fmt.Fprintf(gin.DefaultErrorWriter, "[WARNING] multitemplate: function %s failed: %v\n", name, err.Interface())
+			for i := range results {
+				results[i] = reflect.Zero(typ.Out(i))
+			}
+		}
+		return results
+	}).Interface()
+}
+
+// WithRecover makes Instance recover from panics raised while looking up or
+// building a template. Instead of propagating the panic, fn is called with the
+// template name and the recovered value, and the render.Render it returns
+// (e.g. a fallback error page) is used in place of the template.
+func WithRecover(fn func(name string, r interface{}) render.Render) RendererOption {
+	return func(o *rendererOptions) {
+		o.recoverFunc = fn
+	}
+}
+
+// WithPanicHandler makes Instance recover from panics raised while looking
+// up or building a template, like WithRecover, and render the template
+// registered under name instead, with a PanicInfo as data and a 500 Internal
+// Server Error status. WithRecover takes precedence.
+func WithPanicHandler(name string) RendererOption {
+	return func(o *rendererOptions) {
+		o.panicTemplate = name
+	}
+}
+
+// WithDataHook registers fn to transform the template data before every render,
+// e.g. to add a CSRF token, the current path or flash messages.
+// The context is nil unless the render was created with InstanceCtx.
+func WithDataHook(fn func(c *gin.Context, name string, data interface{}) interface{}) RendererOption {
+	return func(o *rendererOptions) {
+		o.dataHook = fn
+	}
+}
+
+// WithRequestData registers fn to compute values from the request, e.g. the
+// canonical URL or the current path, that are merged into the data of every
+// render. The data is merged like InstanceMerge, but keys set by the handler
+// take precedence over the ones returned by fn. fn only runs for renders
+// created with InstanceCtx, as there is no request otherwise. It runs before
+// the data hook.
+func WithRequestData(fn func(c *gin.Context) map[string]interface{}) RendererOption {
+	return func(o *rendererOptions) {
+		o.requestData = fn
+	}
+}
+
+// WithLenientFuncs makes template functions that return a non-nil error log
+// it to gin.DefaultErrorWriter and render their zero value instead of
+// aborting the render, so one failing helper, e.g. a dashboard widget, does
+// not blank the whole page. Use it with care: failures are easily missed and
+// the page may silently render incomplete or misleading content.
+func WithLenientFuncs() RendererOption {
+	return func(o *rendererOptions) {
+		o.lenientFuncs = true
+	}
+}
+
+// WithFrontMatter makes file and string templates start with an optional
+// YAML front matter block delimited by "---" lines, e.g.
+//
+//	---
+//	title: Dashboard
+//	roles: [admin]
+//	---
+//	<h1>{{ .title }}</h1>
+//
+// The block is removed before the template is parsed and returned by Meta.
+func WithFrontMatter() RendererOption {
+	return func(o *rendererOptions) {
+		o.frontMatter = true
+	}
+}
+
+// WithDuplicateDefineCheck makes file, glob and fs.FS based templates fail to
+// build when the same template name is defined in more than one of their files.
+// Without it the file parsed last silently wins.
+func WithDuplicateDefineCheck() RendererOption {
+	return func(o *rendererOptions) {
+		o.checkDefines = true
+	}
+}
+
+// WithFuncCheck checks the functions called by every template before it is
+// parsed, so a template calling helpers that are not registered fails to
+// build with an error naming all of them, instead of only the first one the
+// parser runs into. Builds happen when templates are added, on reload and in
+// Validate, so missing helpers are reported before the template is rendered.
+func WithFuncCheck() RendererOption {
+	return func(o *rendererOptions) {
+		o.checkFuncs = true
+	}
+}
+
+// WithAllowedFuncs makes templates parsed from files or strings fail to build
+// when they call a function that is not in names, e.g. to let tenants author
+// templates without exposing every helper of the application. Builtin
+// functions such as "len" or "printf" are always allowed, except "call",
+// which calls any function value of the data and must be in names. Templates
+// added as a *template.Template, by a factory or from a parse tree are not
+// checked. The templates are checked once per build.
+func WithAllowedFuncs(names []string) RendererOption {
+	return func(o *rendererOptions) {
+		o.allowedFuncs = make(map[string]bool, len(names))
+		for _, name := range names {
+			o.allowedFuncs[name] = true
+		}
+	}
+}
+
+// WithContextFuncs gives template functions access to the request context,
+// e.g. to set headers or cookies while rendering. fn is called with a nil
+// context when templates are parsed, to learn the function names, and with
+// the request context on every InstanceCtx call (nil for Instance), so the
+// functions it returns must cope with a nil context.
+//
+// The registered templates are shared between concurrent requests, so the
+// functions are never bound on them directly: every render executes a clone
+// of the template with the request functions applied. This costs a clone and
+// re-escaping per render, and keeps requests from seeing each other's functions.
+func WithContextFuncs(fn func(c *gin.Context) template.FuncMap) RendererOption {
+	return func(o *rendererOptions) {
+		o.contextFuncs = fn
+	}
+}
+
+// WithCSPNonce adds a "nonce" template function returning fn(c), the
+// Content-Security-Policy nonce of the current request, for strict CSP:
+//
+//	<script nonce="{{nonce}}">...</script>
+//
+// fn usually reads the nonce a middleware generated and sent in the
+// Content-Security-Policy header, e.g. from c.Get. The function is bound like
+// the ones of WithContextFuncs, so the nonce is only available to renders
+// created with InstanceCtx; with Instance (and so gin's c.HTML) it is empty.
+func WithCSPNonce(fn func(c *gin.Context) string) RendererOption {
+	return func(o *rendererOptions) {
+		o.cspNonce = fn
+	}
+}
+
+// cspNonceKey is the context key of the nonce generated for WithCSP.
+const cspNonceKey = "multitemplate.cspNonce"
+
+// WithCSP sets the Content-Security-Policy header of every render created
+// with InstanceCtx to policy, with each "{nonce}" replaced by a nonce
+// generated for the request, and makes that nonce available to templates
+// like WithCSPNonce does:
+//
+//	WithCSP("script-src 'nonce-{nonce}' 'strict-dynamic'")
+//	<script nonce="{{nonce}}">...</script>
+//
+// All renders of a request share its nonce. Renders created with Instance
+// (and so gin's c.HTML) have no request, so they send no header and the
+// nonce is empty.
+func WithCSP(policy string) RendererOption {
+	return func(o *rendererOptions) {
+		o.cspPolicy = policy
+		o.cspNonce = requestNonce
+	}
+}
+
+// requestNonce returns the nonce of the request c, generating it on first use.
+func requestNonce(c *gin.Context) string {
+	if nonce := c.GetString(cspNonceKey); nonce != "" {
+		return nonce
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	c.Set(cspNonceKey, nonce)
+	return nonce
+}
+
+// WithMissingAsEmpty makes Instance render an empty body instead of panicking
+// when no template is registered under the requested name, e.g. for optional
+// widget slots. The status chosen by the handler is kept.
+func WithMissingAsEmpty() RendererOption {
+	return func(o *rendererOptions) {
+		o.missingAsEmpty = true
+	}
+}
+
+// WithMissingStatus works like WithMissingAsEmpty but also replaces the
+// response status with status for templates that are not registered.
+func WithMissingStatus(status int) RendererOption {
+	return func(o *rendererOptions) {
+		o.missingAsEmpty = true
+		o.missingStatus = status
+	}
+}
+
+// WithEmptyStatus replaces the 204 No Content status written by InstanceEmpty.
+func WithEmptyStatus(status int) RendererOption {
+	return func(o *rendererOptions) {
+		o.emptyStatus = status
+	}
+}
+
+// WithPrettyHTML re-indents rendered text/html output, one tag per line, to
+// make it readable while debugging. The content of pre, textarea, script and
+// style elements is kept as is. Output is buffered and reformatted on every
+// render, so this is meant for development only.
+func WithPrettyHTML() RendererOption {
+	return func(o *rendererOptions) {
+		o.prettyHTML = true
+	}
+}
+
+// WithMissingPlaceholder renders the result of fn in place of every missing
+// or nil field a template prints while gin is in debug mode, e.g.
+// "[missing:User.Name]" instead of nothing or "<no value>", so gaps in the
+// data stand out during development. Only actions printing a field are
+// affected, conditions and function arguments are not. The mode is checked
+// when a template is built, so in release mode the output is unchanged.
+func WithMissingPlaceholder(fn func(field string) string) RendererOption {
+	return func(o *rendererOptions) {
+		o.missingPlaceholder = fn
+	}
+}
+
+// WithAutoMinify minifies rendered text/html output while gin is in release
+// mode: whitespace runs are collapsed and comments are removed, while the
+// content of pre, textarea, script and style elements is kept as is. In debug
+// mode the output stays readable. The mode is checked on every render.
+func WithAutoMinify() RendererOption {
+	return func(o *rendererOptions) {
+		o.autoMinify = true
+	}
+}
+
+// WithMaxDepth aborts a render with ErrMaxDepth when templates include each
+// other more than n levels deep, the template rendered counting as the first
+// level, e.g. to stop runaway recursion in tenant-authored templates long
+// before the stack is exhausted. Every render executes a clone of the
+// template that tracks its own depth.
+func WithMaxDepth(n int) RendererOption {
+	return func(o *rendererOptions) {
+		o.maxDepth = n
+	}
+}
+
+// WithServerTiming buffers every render and adds its duration, from the
+// start of execution until the output is ready, to the Server-Timing header,
+// e.g. "render;dur=12.3" in milliseconds, so it shows in the network panel of
+// the browser devtools. Existing Server-Timing values are kept.
+func WithServerTiming() RendererOption {
+	return func(o *rendererOptions) {
+		o.serverTiming = true
+	}
+}
+
+// WithPostProcessor registers fn to transform the rendered output of every
+// template before it is written, e.g. to inline critical CSS or rewrite asset
+// URLs. Processors run in the order they were registered, each receiving the
+// output of the previous one. Output is buffered, and when a processor fails
+// nothing is written and its error is returned by the render.
+func WithPostProcessor(fn func(name string, out []byte) ([]byte, error)) RendererOption {
+	return func(o *rendererOptions) {
+		o.postProcessors = append(o.postProcessors, fn)
+	}
+}
+
+// WithNoStore sets whether rendered responses carry a "Cache-Control: no-store"
+// header, so browsers never show stale pages while templates are edited.
+// It is enabled by default for dynamic renderers and disabled for static
+// ones; pass false to test caching behavior with a dynamic renderer.
+func WithNoStore(enabled bool) RendererOption {
+	return func(o *rendererOptions) {
+		o.noStore = enabled
+	}
+}
+
+// WithCachePolicy sets the default Cache-Control header sent with every
+// rendered template, e.g. "private, max-age=60". It takes precedence over
+// WithNoStore; templates can override it with SetCachePolicy.
+func WithCachePolicy(policy string) RendererOption {
+	return func(o *rendererOptions) {
+		o.cachePolicy = policy
+	}
+}
+
+// WithContentType replaces the Content-Type of rendered responses,
+// "text/html; charset=utf-8" by default, with ct, e.g. a bare "text/html"
+// for proxies that expect it. A Content-Type set by the handler is kept.
+func WithContentType(ct string) RendererOption {
+	return func(o *rendererOptions) {
+		o.contentType = ct
+	}
+}
+
+// WithWriterWrapper registers fn to wrap the writer every template render
+// writes its body to, e.g. with a writer counting the bytes written for
+// tracing. fn is called once per render, when the response is written, with
+// the response writer, and applies to buffered and unbuffered renders alike.
+// Buffers of WithWriteBuffer write through the returned writer.
+func WithWriterWrapper(fn func(w io.Writer) io.Writer) RendererOption {
+	return func(o *rendererOptions) {
+		o.writerWrapper = fn
+	}
+}
+
+// WithWriteBuffer makes templates rendered straight into the response write
+// through a bufio.Writer of size bytes, flushed once the template executed,
+// instead of making a write (and usually a syscall) per template fragment.
+// It is useful for large pages with a lot of interpolation. Renders that are
+// already buffered, e.g. with WithPrettyHTML or a timeout, are not affected.
+func WithWriteBuffer(size int) RendererOption {
+	return func(o *rendererOptions) {
+		o.writeBufferSize = size
+	}
+}
+
+// WithOutputCache caches the rendered output of the templates flagged with
+// SetCacheable, keeping the size most recently used outputs. Cached output
+// is dropped when it is evicted, by InvalidateOutput and by ReloadAll.
+func WithOutputCache(size int) RendererOption {
+	return func(o *rendererOptions) {
+		o.outputCacheSize = size
+	}
+}
+
+// WithChecksumReload makes ReloadAll (and version changes) skip the templates
+// whose source files have the same content as when they were last built,
+// e.g. when a deploy tool rewrites files without changing them, see HasChanged.
+func WithChecksumReload() RendererOption {
+	return func(o *rendererOptions) {
+		o.checksumReload = true
+	}
+}
+
+// WithDefaultLocale sets the locale InstanceLocale falls back to when a
+// localized template has no file for the requested locale, "en" by default.
+func WithDefaultLocale(locale string) RendererOption {
+	return func(o *rendererOptions) {
+		o.defaultLocale = locale
+	}
+}
+
+// WithSourceTransform registers fn to rewrite the source of every file and
+// string template before it is parsed, e.g. to expand custom macros or strip
+// development only blocks. name is the name the source is parsed under. An
+// error fails the build of the template.
+func WithSourceTransform(fn func(name, src string) (string, error)) RendererOption {
+	return func(o *rendererOptions) {
+		o.sourceTransform = fn
+	}
+}
+
+// WithBaseDir names the templates parsed from files by their slash separated
+// path relative to dir, e.g. "partials/sidebar.html", instead of their base
+// name. The same dir applies to OS files and to paths inside an fs.FS, so
+// {{template}} references resolve identically when templates are loaded from
+// disk in development and from an embed.FS in production.
+func WithBaseDir(dir string) RendererOption {
+	return func(o *rendererOptions) {
+		o.baseDir = dir
+	}
+}
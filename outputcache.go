@@ -0,0 +1,158 @@
+package multitemplate
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// outputCache is a least recently used cache of rendered template output.
+type outputCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type outputCacheEntry struct {
+	key  string
+	body []byte
+}
+
+func newOutputCache(size int) *outputCache {
+	return &outputCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// outputKey returns the cache key of a render of the template name (or of
+// its associated template section) with data, hashing the JSON encoding of
+// data. It returns false if data cannot be encoded.
+func outputKey(name, section string, data interface{}) (string, bool) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(b)
+	return name + "\x00" + section + "\x00" + hex.EncodeToString(sum[:]), true
+}
+
+func (oc *outputCache) get(key string) ([]byte, bool) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	elem, ok := oc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	oc.order.MoveToFront(elem)
+	return elem.Value.(*outputCacheEntry).body, true
+}
+
+func (oc *outputCache) put(key string, body []byte) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if elem, ok := oc.entries[key]; ok {
+		elem.Value.(*outputCacheEntry).body = body
+		oc.order.MoveToFront(elem)
+		return
+	}
+	oc.entries[key] = oc.order.PushFront(&outputCacheEntry{key: key, body: body})
+	for oc.order.Len() > oc.size {
+		oldest := oc.order.Back()
+		oc.order.Remove(oldest)
+		delete(oc.entries, oldest.Value.(*outputCacheEntry).key)
+	}
+}
+
+// invalidate removes the output of the template name, or all output if name is empty.
+func (oc *outputCache) invalidate(name string) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	for key, elem := range oc.entries {
+		if name == "" || strings.HasPrefix(key, name+"\x00") {
+			oc.order.Remove(elem)
+			delete(oc.entries, key)
+		}
+	}
+}
+
+// cachedRender writes output served from the output cache.
+type cachedRender struct {
+	body []byte
+	// contentType replaces the HTML Content-Type if set.
+	contentType string
+}
+
+var _ render.Render = cachedRender{}
+
+// Render (cachedRender) writes the cached output into the response body.
+func (r cachedRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	_, err := w.Write(r.body)
+	return err
+}
+
+// WriteContentType (cachedRender) writes the ContentType of the cached template.
+func (r cachedRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		if r.contentType != "" {
+			header["Content-Type"] = []string{r.contentType}
+		} else {
+			header["Content-Type"] = htmlContentType
+		}
+	}
+}
+
+// SetCacheable implements Render.SetCacheable and DynamicRender.SetCacheable.
+func (r *registry) SetCacheable(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cacheable == nil {
+		r.cacheable = make(map[string]bool)
+	}
+	for _, name := range names {
+		r.cacheable[name] = true
+	}
+}
+
+// InvalidateOutput implements Render.InvalidateOutput and DynamicRender.InvalidateOutput.
+func (r *registry) InvalidateOutput(name string) {
+	if r.outputCache != nil {
+		r.outputCache.invalidate(name)
+	}
+}
+
+// cachedOutput looks up the output of a render in the output cache. It
+// returns the cache key to store the output under on a miss, or an empty key
+// if the render is not cacheable.
+func (r *registry) cachedOutput(name, section string, data interface{}) (body []byte, key string) {
+	if r.outputCache == nil {
+		return nil, ""
+	}
+	r.mu.RLock()
+	cacheable := r.cacheable[name]
+	r.mu.RUnlock()
+	if !cacheable {
+		return nil, ""
+	}
+
+	key, ok := outputKey(name, section, data)
+	if !ok {
+		return nil, ""
+	}
+	body, _ = r.outputCache.get(key)
+	return body, key
+}
@@ -0,0 +1,71 @@
+package multitemplate
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputCache(t *testing.T) {
+	calls := 0
+	r := New(WithOutputCache(2))
+	r.AddFromStringsFuncs("index", template.FuncMap{
+		"count": func() int {
+			calls++
+			return calls
+		},
+	}, "{{ .name }} {{ count }}")
+	r.SetCacheable("index")
+
+	render := func(name string) string {
+		w, err := renderResponse(r, "index", gin.H{"name": name})
+		assert.NoError(t, err)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "a 1", render("a"))
+	assert.Equal(t, "a 1", render("a"))
+	assert.Equal(t, "b 2", render("b"))
+	assert.Equal(t, "c 3", render("c"))
+	// a was evicted as the least recently used output.
+	assert.Equal(t, "a 4", render("a"))
+	assert.Equal(t, "c 3", render("c"))
+
+	r.InvalidateOutput("index")
+	assert.Equal(t, "c 5", render("c"))
+	assert.Equal(t, "c 5", render("c"))
+}
+
+func TestOutputCacheNotCacheable(t *testing.T) {
+	r := NewDynamic(WithOutputCache(10))
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	w, err := renderResponse(r, "index", gin.H{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+	assert.Empty(t, r.registry().outputCache.entries)
+}
+
+func TestOutputCacheContentType(t *testing.T) {
+	r := New(WithOutputCache(10))
+	r.AddTextFromString("plain", "Hello {{ .name }}")
+	r.SetCacheable("plain")
+
+	for i := 0; i < 2; i++ {
+		w, err := renderResponse(r, "plain", gin.H{"name": "text"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello text", w.Body.String())
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	}
+
+	r = New(WithOutputCache(10), WithContentType("application/xhtml+xml"))
+	r.AddFromString("index", "Hello {{ .name }}")
+	r.SetCacheable("index")
+	for i := 0; i < 2; i++ {
+		w, err := renderResponse(r, "index", gin.H{"name": "xhtml"})
+		assert.NoError(t, err)
+		assert.Equal(t, "application/xhtml+xml", w.Header().Get("Content-Type"))
+	}
+}
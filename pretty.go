@@ -0,0 +1,148 @@
+package multitemplate
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// voidElements never have an end tag and do not increase the indentation.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// preservedElements have whitespace sensitive content that is written as is.
+var preservedElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// indentHTML re-indents src with one tag or text run per line.
+// The content of preserved elements is not modified.
+func indentHTML(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writeLine := func(depth int, b []byte) {
+		buf.Write(bytes.Repeat([]byte("  "), depth))
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(src))
+	depth, preserved := 0, 0
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				return buf.Bytes(), nil
+			}
+			return nil, z.Err()
+		}
+
+		raw := z.Raw()
+		name, _ := z.TagName()
+		tag := string(name)
+		if preserved > 0 {
+			buf.Write(raw)
+			switch {
+			case tt == html.StartTagToken && preservedElements[tag]:
+				preserved++
+			case tt == html.EndTagToken && preservedElements[tag]:
+				preserved--
+				if preserved == 0 {
+					buf.WriteByte('\n')
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			if text := bytes.TrimSpace(raw); len(text) > 0 {
+				writeLine(depth, text)
+			}
+		case html.StartTagToken:
+			switch {
+			case preservedElements[tag]:
+				buf.Write(bytes.Repeat([]byte("  "), depth))
+				buf.Write(raw)
+				preserved++
+			case voidElements[tag]:
+				writeLine(depth, raw)
+			default:
+				writeLine(depth, raw)
+				depth++
+			}
+		case html.EndTagToken:
+			if depth > 0 {
+				depth--
+			}
+			writeLine(depth, raw)
+		default:
+			writeLine(depth, raw)
+		}
+	}
+}
+
+// minifyHTML collapses every whitespace run of src into a single space and
+// removes comments. The content of preserved elements is not modified.
+func minifyHTML(src []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(src))
+	preserved := 0
+	// space is set while the output ends with a collapsed whitespace run,
+	// which continues across removed comments.
+	space := false
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() == io.EOF {
+				return buf.Bytes(), nil
+			}
+			return nil, z.Err()
+		}
+
+		raw := z.Raw()
+		name, _ := z.TagName()
+		tag := string(name)
+		switch {
+		case preserved > 0:
+			buf.Write(raw)
+		case tt == html.TextToken:
+			space = collapseSpace(&buf, raw, space)
+			continue
+		case tt == html.CommentToken:
+			continue
+		default:
+			buf.Write(raw)
+		}
+		space = false
+
+		switch {
+		case tt == html.StartTagToken && preservedElements[tag]:
+			preserved++
+		case tt == html.EndTagToken && preservedElements[tag] && preserved > 0:
+			preserved--
+		}
+	}
+}
+
+// collapseSpace writes text to buf with every run of ASCII whitespace
+// replaced by a single space. space reports whether buf already ends with a
+// collapsed run; the returned value reports the same after writing text.
+func collapseSpace(buf *bytes.Buffer, text []byte, space bool) bool {
+	for _, c := range text {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\f':
+			if !space {
+				buf.WriteByte(' ')
+			}
+			space = true
+		default:
+			buf.WriteByte(c)
+			space = false
+		}
+	}
+	return space
+}
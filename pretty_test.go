@@ -0,0 +1,66 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndentHTML(t *testing.T) {
+	out, err := indentHTML([]byte(`<!DOCTYPE html><html><head><meta charset="utf-8">` +
+		`<style>p { color: red; }</style></head><body><p>Hello <b>world</b></p>` +
+		"<pre>  keep\n  this</pre><br/></body></html>"))
+	assert.NoError(t, err)
+	assert.Equal(t, `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <style>p { color: red; }</style>
+  </head>
+  <body>
+    <p>
+      Hello
+      <b>
+        world
+      </b>
+    </p>
+    <pre>  keep
+  this</pre>
+    <br/>
+  </body>
+</html>
+`, string(out))
+}
+
+func TestWithPrettyHTML(t *testing.T) {
+	r := New(WithPrettyHTML())
+	r.AddFromString("index", "<div><p>{{ .name }}</p></div>")
+
+	w, err := renderResponse(r, "index", map[string]string{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<div>\n  <p>\n    index\n  </p>\n</div>\n", w.Body.String())
+}
+
+func TestMinifyHTML(t *testing.T) {
+	out, err := minifyHTML([]byte("<ul>\n  <li>Hello   <b>world</b></li>\n  <!-- note -->\n</ul>\n" +
+		"<pre>  keep\n  this</pre>\n<script>\n  if (a  <  b) {}\n</script>\n<textarea>  x  </textarea>"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<ul> <li>Hello <b>world</b></li> </ul> "+
+		"<pre>  keep\n  this</pre> <script>\n  if (a  <  b) {}\n</script> <textarea>  x  </textarea>", string(out))
+}
+
+func TestWithAutoMinify(t *testing.T) {
+	r := New(WithAutoMinify())
+	r.AddFromString("index", "<div>\n  <p>{{ .name }}</p>\n</div>")
+
+	w, err := renderResponse(r, "index", map[string]string{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<div>\n  <p>index</p>\n</div>", w.Body.String())
+
+	gin.SetMode(gin.ReleaseMode)
+	defer gin.SetMode(gin.DebugMode)
+	w, err = renderResponse(r, "index", map[string]string{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<div> <p>index</p> </div>", w.Body.String())
+}
@@ -0,0 +1,52 @@
+package multitemplate
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRecentErrors is the number of render failures kept for RecentErrors.
+const maxRecentErrors = 50
+
+// RenderError is a failed render of a template, see RecentErrors.
+type RenderError struct {
+	// Name is the name the template is registered under.
+	Name string
+	Time time.Time
+	Err  error
+}
+
+// recentErrors is a ring buffer of the last render failures.
+type recentErrors struct {
+	mu     sync.Mutex
+	errors []RenderError
+	next   int
+}
+
+func (re *recentErrors) add(name string, err error) {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	failure := RenderError{Name: name, Time: time.Now(), Err: err}
+	if len(re.errors) < maxRecentErrors {
+		re.errors = append(re.errors, failure)
+		return
+	}
+	re.errors[re.next] = failure
+	re.next = (re.next + 1) % maxRecentErrors
+}
+
+// list returns the failures, oldest first.
+func (re *recentErrors) list() []RenderError {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	list := make([]RenderError, 0, len(re.errors))
+	list = append(list, re.errors[re.next:]...)
+	return append(list, re.errors[:re.next]...)
+}
+
+// RecentErrors implements Render.RecentErrors and DynamicRender.RecentErrors.
+func (r *registry) RecentErrors() []RenderError {
+	return r.recentErrors.list()
+}
@@ -0,0 +1,44 @@
+package multitemplate
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentErrors(t *testing.T) {
+	errProcess := errors.New("process failed")
+	r := New(WithPostProcessor(func(name string, out []byte) ([]byte, error) {
+		if name == "broken" {
+			return nil, errProcess
+		}
+		return out, nil
+	}))
+	r.AddFromString("index", "Welcome")
+	r.AddFromString("broken", "Broken")
+	assert.Empty(t, r.RecentErrors())
+
+	assert.NoError(t, r.Instance("index", nil).Render(httptest.NewRecorder()))
+	for i := 0; i < maxRecentErrors+2; i++ {
+		assert.Error(t, r.Instance("broken", nil).Render(httptest.NewRecorder()))
+	}
+
+	failures := r.RecentErrors()
+	assert.Len(t, failures, maxRecentErrors)
+	assert.Equal(t, "broken", failures[0].Name)
+	assert.ErrorIs(t, failures[0].Err, errProcess)
+	assert.False(t, failures[0].Time.After(failures[len(failures)-1].Time))
+}
+
+func TestRecentErrorsOrder(t *testing.T) {
+	var re recentErrors
+	for i := 0; i < maxRecentErrors+3; i++ {
+		re.add(fmt.Sprint(i), nil)
+	}
+	list := re.list()
+	assert.Equal(t, "3", list[0].Name)
+	assert.Equal(t, fmt.Sprint(maxRecentErrors+2), list[len(list)-1].Name)
+}
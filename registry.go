@@ -0,0 +1,660 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// registry stores the template builders and renderer options shared by
+// Render and DynamicRender. A static registry keeps the template built at
+// registration time while a dynamic one rebuilds it on every Instance call.
+type registry struct {
+	mu       sync.RWMutex
+	builders map[string]*templateBuilder
+	dynamic  bool
+	opts     rendererOptions
+
+	// gen counts the changes of the builders, which mirror copies into the
+	// renderer map.
+	gen atomic.Uint64
+	// mirrorMu guards the renderer map while mirror updates it, along with
+	// the generation and the length of the map after the last update.
+	mirrorMu  sync.RWMutex
+	mirrored  uint64
+	mirrorLen int
+
+	// version requested with SetVersion and version the templates were last
+	// reloaded for.
+	version      string
+	builtVersion string
+
+	// outputCache holds the output of the cacheable templates, see WithOutputCache.
+	outputCache *outputCache
+	cacheable   map[string]bool
+
+	// timeouts are the render timeouts set with SetTimeout.
+	timeouts map[string]time.Duration
+
+	recentErrors recentErrors
+
+	// shells are the layouts cached with CacheShell.
+	shells map[string]shell
+	// locales are the variants of the templates added with AddLocalized,
+	// keyed by name and locale. They are not registered under a name of
+	// their own; the name renders the variant of the default locale.
+	locales map[string]map[string]*templateBuilder
+	// cachePolicies are the Cache-Control headers set with SetCachePolicy.
+	cachePolicies map[string]string
+	// errorPages are the templates added with AddErrorPage, keyed by status.
+	errorPages map[int]string
+}
+
+// instanceOptions modify a single render created by instance.
+type instanceOptions struct {
+	// section is the associated template to execute instead of the root one.
+	section string
+	// blocks are the associated templates to execute one after another
+	// instead of the root one.
+	blocks  []renderBlock
+	timeout time.Duration
+	// uncached renders bypass the output cache.
+	uncached bool
+	// postProcess transforms the output after the post processors of the renderer.
+	postProcess func(out []byte) ([]byte, error)
+	// funcs are bound on a clone of the template, see InstanceFuncs.
+	funcs template.FuncMap
+	// locale selects the variant of a localized template, see InstanceLocale.
+	locale string
+}
+
+func newRegistry(dynamic bool, opts []RendererOption) *registry {
+	r := &registry{
+		builders: make(map[string]*templateBuilder),
+		dynamic:  dynamic,
+	}
+	r.opts.noStore = dynamic
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	if r.opts.outputCacheSize > 0 {
+		r.outputCache = newOutputCache(r.opts.outputCacheSize)
+	}
+	return r
+}
+
+// Exists implements Render.Exists and DynamicRender.Exists.
+func (r *registry) Exists(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.builders[name]
+	return ok
+}
+
+// Build implements Render.Build and DynamicRender.Build.
+func (r *registry) Build(name string) (*template.Template, error) {
+	built, err := r.build(name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, ok := built.(*template.Template)
+	if !ok {
+		return nil, fmt.Errorf("template %s is not an html/template template", name)
+	}
+	if !r.shared(name) {
+		return tmpl, nil
+	}
+	// the kept template is cloned for every render, so hand out a copy of a
+	// never executed one the caller may execute
+	base, err := r.cloneBase(name, "")
+	if err != nil {
+		return nil, err
+	}
+	return base.(*template.Template).Clone()
+}
+
+// shared reports whether the template of name is built once and kept by the
+// registry, other than a *template.Template added as is.
+func (r *registry) shared(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	builder, ok := r.builders[name]
+	return ok && (!r.dynamic || builder.pinned) && builder.buildType != templateType
+}
+
+// build works like Build but also returns trusted templates.
+func (r *registry) build(name string) (executor, error) {
+	return r.buildLocale(name, "")
+}
+
+// buildLocale works like build for the variant locale of the localized
+// template name, or for name itself if locale is empty.
+func (r *registry) buildLocale(name, locale string) (executor, error) {
+	if err := r.syncVersion(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	builder, err := r.builder(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	return builder.current(r.dynamic)
+}
+
+// builder returns the builder of name, or of its variant locale if locale is
+// not empty. r.mu must be held.
+func (r *registry) builder(name, locale string) (*templateBuilder, error) {
+	if locale != "" {
+		if builder, ok := r.locales[name][locale]; ok {
+			return builder, nil
+		}
+		return nil, fmt.Errorf("%w: %s has no locale %s", ErrTemplateNotFound, name, locale)
+	}
+	if builder, ok := r.builders[name]; ok {
+		return builder, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+}
+
+// current returns the template of the builder: built from its sources if it
+// belongs to a dynamic renderer and is not pinned, the kept one otherwise.
+func (tb *templateBuilder) current(dynamic bool) (executor, error) {
+	if dynamic && !tb.pinned {
+		return tb.buildExecutor()
+	}
+	if tb.buildType == lazyTemplateType || tb.buildType == fsGlobTemplateType {
+		tmpl, err := tb.lazy.get(tb.templateName)
+		if err != nil {
+			return nil, newTemplateError(err)
+		}
+		return tmpl, nil
+	}
+	return tb.kept(), nil
+}
+
+// executable returns the template registered under name ready to be
+// executed for the request c. With context functions the template is cloned
+// and the functions are bound on the clone, so the shared template is never
+// executed and can still be cloned.
+func (r *registry) executable(c *gin.Context, name string) (executor, error) {
+	return r.executableWith(c, name, instanceOptions{})
+}
+
+// executableWith works like executable for the variant opts.locale of name,
+// if set, and binds the functions of opts.funcs on the clone as well, see
+// InstanceFuncs.
+func (r *registry) executableWith(c *gin.Context, name string, opts instanceOptions) (executor, error) {
+	extra := opts.funcs
+	tmpl, err := r.buildLocale(name, opts.locale)
+	if err != nil {
+		return nil, err
+	}
+	funcMap := r.opts.requestFuncs(c)
+	if r.opts.maxDepth > 0 || len(extra) > 0 {
+		if funcMap == nil {
+			funcMap = template.FuncMap{}
+		}
+		if r.opts.maxDepth > 0 {
+			for name, fn := range depthFuncs(r.opts.maxDepth) {
+				funcMap[name] = fn
+			}
+		}
+		for name, fn := range extra {
+			funcMap[name] = fn
+		}
+	}
+	if funcMap == nil {
+		return tmpl, nil
+	}
+	clone, err := cloneWithFuncs(tmpl, funcMap)
+	if err != nil {
+		// the shared template executed, e.g. before SetFlagProvider was
+		// called, and cannot be cloned anymore
+		base, baseErr := r.cloneBase(name, opts.locale)
+		if baseErr != nil {
+			return nil, err
+		}
+		return cloneWithFuncs(base, funcMap)
+	}
+	return clone, err
+}
+
+// cloneBase returns a template built for the builder of name, or of its
+// variant locale, that is never executed, so it can always be cloned. It is
+// built on first use and kept until the builder is rebuilt.
+func (r *registry) cloneBase(name, locale string) (executor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	builder, err := r.builder(name, locale)
+	if err != nil {
+		return nil, err
+	}
+	if builder.base == nil {
+		base, err := builder.buildExecutor()
+		if err != nil {
+			return nil, err
+		}
+		builder.base = base
+	}
+	return builder.base, nil
+}
+
+// DefinedTemplates implements Render.DefinedTemplates and DynamicRender.DefinedTemplates.
+func (r *registry) DefinedTemplates(name string) ([]string, error) {
+	tmpl, err := r.build(name)
+	if err != nil {
+		return nil, err
+	}
+
+	names := definedTemplates(tmpl)
+	sort.Strings(names)
+	return names, nil
+}
+
+// Merge implements Render.Merge and DynamicRender.Merge.
+func (r *registry) Merge(other Renderer) error {
+	var src *registry
+	switch o := other.(type) {
+	case Render:
+		src = o.registry()
+	case DynamicRender:
+		src = o.registry()
+	default:
+		return fmt.Errorf("cannot merge renderer of type %T", other)
+	}
+	if src == r {
+		return nil
+	}
+
+	defer lockMerge(r, src)()
+
+	builders := make(map[string]*templateBuilder, len(src.builders))
+	for _, name := range sortedNames(src.builders) {
+		builder := src.builders[name]
+		if _, ok := r.builders[name]; ok {
+			return fmt.Errorf("template %s already exists", name)
+		}
+		merged, err := r.mergeBuilder(src, name, builder)
+		if err != nil {
+			return err
+		}
+		builders[name] = merged
+	}
+	locales := make(map[string]map[string]*templateBuilder, len(src.locales))
+	for _, name := range sortedNames(src.locales) {
+		variants := make(map[string]*templateBuilder, len(src.locales[name]))
+		for locale, builder := range src.locales[name] {
+			merged, err := r.mergeBuilder(src, name, builder)
+			if err != nil {
+				return err
+			}
+			variants[locale] = merged
+		}
+		locales[name] = variants
+	}
+	for status := range src.errorPages {
+		if _, ok := r.errorPages[status]; ok {
+			return fmt.Errorf("error page for status %d already exists", status)
+		}
+	}
+
+	for name, builder := range builders {
+		r.builders[name] = builder
+	}
+	r.gen.Add(1)
+	r.locales = mergeMap(r.locales, locales)
+	r.errorPages = mergeMap(r.errorPages, src.errorPages)
+	r.cachePolicies = mergeMap(r.cachePolicies, src.cachePolicies)
+	r.timeouts = mergeMap(r.timeouts, src.timeouts)
+	r.cacheable = mergeMap(r.cacheable, src.cacheable)
+	return nil
+}
+
+// lockMerge locks r for writing and src for reading in the order of their
+// addresses, so that merges in opposite directions cannot deadlock, and
+// returns the function unlocking both.
+func lockMerge(r, src *registry) (unlock func()) {
+	if uintptr(unsafe.Pointer(r)) < uintptr(unsafe.Pointer(src)) {
+		r.mu.Lock()
+		src.mu.RLock()
+	} else {
+		src.mu.RLock()
+		r.mu.Lock()
+	}
+	return func() {
+		src.mu.RUnlock()
+		r.mu.Unlock()
+	}
+}
+
+// mergeBuilder returns a copy of builder, registered on src under name, to
+// register on r.
+func (r *registry) mergeBuilder(src *registry, name string, builder *templateBuilder) (*templateBuilder, error) {
+	merged := *builder
+	merged.settings = &r.opts
+	switch {
+	case r.dynamic && !src.dynamic && builder.tmpl != nil:
+		merged = templateBuilder{
+			buildType:    templateType,
+			templateName: name,
+			tmpl:         builder.tmpl,
+			options:      *NewTemplateOptions(),
+			settings:     &r.opts,
+		}
+	case !r.dynamic && src.dynamic:
+		if err := merged.keep(); err != nil {
+			return nil, err
+		}
+	}
+	return &merged, nil
+}
+
+// mergeMap returns dst with the entries of src added, allocating dst if
+// needed.
+func mergeMap[K comparable, V any](dst, src map[K]V) map[K]V {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[K]V, len(src))
+	}
+	for key, value := range src {
+		dst[key] = value
+	}
+	return dst
+}
+
+// SetCommonFiles implements Render.SetCommonFiles and DynamicRender.SetCommonFiles.
+func (r *registry) SetCommonFiles(files ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts.commonFiles = files
+}
+
+// SetDebugFuncs implements Render.SetDebugFuncs and DynamicRender.SetDebugFuncs.
+func (r *registry) SetDebugFuncs(funcMap template.FuncMap) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts.debugFuncs = funcMap
+}
+
+// SetFlagProvider implements Render.SetFlagProvider and DynamicRender.SetFlagProvider.
+func (r *registry) SetFlagProvider(fn func(name string, c *gin.Context) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts.flagProvider = fn
+}
+
+// SetResolver implements Render.SetResolver and DynamicRender.SetResolver.
+func (r *registry) SetResolver(fn func(name string, c *gin.Context) string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts.resolver = fn
+}
+
+// resolve returns the template to render for name, see SetResolver.
+func (r *registry) resolve(c *gin.Context, name string) string {
+	r.mu.RLock()
+	resolver := r.opts.resolver
+	r.mu.RUnlock()
+	if resolver == nil {
+		return name
+	}
+	if resolved := resolver(name, c); resolved != "" {
+		return resolved
+	}
+	return name
+}
+
+// SetSRIManifest implements Render.SetSRIManifest and DynamicRender.SetSRIManifest.
+func (r *registry) SetSRIManifest(manifest map[string]string) {
+	copied := make(map[string]string, len(manifest))
+	for asset, integrity := range manifest {
+		copied[asset] = integrity
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opts.sriManifest = copied
+}
+
+// TemplatesForFile implements Render.TemplatesForFile and DynamicRender.TemplatesForFile.
+func (r *registry) TemplatesForFile(path string) []string {
+	target := cleanPath(path)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for _, name := range sortedNames(r.builders) {
+		builder := r.builders[name]
+		if builder.zipPath != "" && cleanPath(builder.zipPath) == target {
+			names = append(names, name)
+			continue
+		}
+		files, err := builder.resolveFiles()
+		if err != nil {
+			continue
+		}
+		for _, file := range builder.withCommonFiles(files) {
+			if cleanPath(file) == target {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// cleanPath returns path in a form comparable with other cleaned paths.
+func cleanPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// Instance implements Render.Instance and DynamicRender.Instance.
+func (r *registry) Instance(name string, data interface{}) render.Render {
+	return r.InstanceCtx(nil, name, data)
+}
+
+// InstanceCtx implements Render.InstanceCtx and DynamicRender.InstanceCtx.
+func (r *registry) InstanceCtx(c *gin.Context, name string, data interface{}) render.Render {
+	name = r.resolve(c, name)
+	return r.headers(name, r.instance(c, name, data, instanceOptions{}))
+}
+
+// InstanceWith implements Render.InstanceWith and DynamicRender.InstanceWith.
+func (r *registry) InstanceWith(tmpl *template.Template, data interface{}) render.Render {
+	if tmpl == nil {
+		panic("template cannot be nil")
+	}
+	name := tmpl.Name()
+	if r.opts.dataHook != nil {
+		data = r.opts.dataHook(nil, name, data)
+	}
+	return r.headers(name, r.render(name, tmpl, data, instanceOptions{uncached: true}, ""))
+}
+
+// InstanceFuncs implements Render.InstanceFuncs and DynamicRender.InstanceFuncs.
+func (r *registry) InstanceFuncs(name string, funcMap template.FuncMap, data interface{}) render.Render {
+	name = r.resolve(nil, name)
+	return r.headers(name, r.instance(nil, name, data, instanceOptions{funcs: funcMap, uncached: true}))
+}
+
+// InstanceTimeout implements Render.InstanceTimeout and DynamicRender.InstanceTimeout.
+func (r *registry) InstanceTimeout(name string, data interface{}, d time.Duration) render.Render {
+	name = r.resolve(nil, name)
+	return r.headers(name, r.instance(nil, name, data, instanceOptions{timeout: d}))
+}
+
+// SetTimeout implements Render.SetTimeout and DynamicRender.SetTimeout.
+func (r *registry) SetTimeout(name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timeouts == nil {
+		r.timeouts = make(map[string]time.Duration)
+	}
+	if d <= 0 {
+		delete(r.timeouts, name)
+		return
+	}
+	r.timeouts[name] = d
+}
+
+// postProcessor returns the chained post processors for the template name,
+// or nil if there are none.
+func (r *registry) postProcessor(name string) func(out []byte) ([]byte, error) {
+	processors := r.opts.postProcessors
+	if len(processors) == 0 {
+		return nil
+	}
+	return func(out []byte) ([]byte, error) {
+		for _, process := range processors {
+			var err error
+			if out, err = process(name, out); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	}
+}
+
+// contentType returns the Content-Type the template name renders as, or an
+// empty string for the default one.
+func (r *registry) contentType(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if builder, ok := r.builders[name]; ok {
+		return builder.contentType
+	}
+	return ""
+}
+
+// chainProcessors returns a processor running first and then second,
+// skipping the nil ones.
+func chainProcessors(first, second func(out []byte) ([]byte, error)) func(out []byte) ([]byte, error) {
+	if first == nil {
+		return second
+	}
+	if second == nil {
+		return first
+	}
+	return func(out []byte) ([]byte, error) {
+		out, err := first(out)
+		if err != nil {
+			return nil, err
+		}
+		return second(out)
+	}
+}
+
+// instance returns the render of the template registered under name, which
+// must already be resolved for c, see resolve.
+func (r *registry) instance(c *gin.Context, name string, data interface{}, opts instanceOptions) (rr render.Render) {
+	if r.opts.recoverFunc != nil {
+		defer func() {
+			if p := recover(); p != nil {
+				rr = r.opts.recoverFunc(name, p)
+			}
+		}()
+	} else if r.opts.panicTemplate != "" && name != r.opts.panicTemplate {
+		defer func() {
+			if p := recover(); p != nil {
+				rr = r.panicPage(name, p)
+			}
+		}()
+	}
+
+	if !r.Exists(name) && r.opts.missingAsEmpty {
+		return emptyRender{status: r.opts.missingStatus}
+	}
+
+	if r.opts.cspPolicy != "" && c != nil {
+		c.Header("Content-Security-Policy", strings.ReplaceAll(r.opts.cspPolicy, "{nonce}", requestNonce(c)))
+	}
+	if r.opts.requestData != nil && c != nil {
+		data = withRequestData(data, r.opts.requestData(c))
+	}
+	if r.opts.dataHook != nil {
+		data = r.opts.dataHook(c, name, data)
+	}
+
+	if opts.timeout <= 0 {
+		r.mu.RLock()
+		opts.timeout = r.timeouts[name]
+		r.mu.RUnlock()
+	}
+
+	var cacheKey string
+	if !opts.uncached {
+		var body []byte
+		if body, cacheKey = r.cachedOutput(name, opts.section, data); body != nil {
+			return cachedRender{body: body, contentType: r.contentType(name)}
+		}
+	}
+
+	tmpl, err := r.executableWith(c, name, opts)
+	if err != nil {
+		panic(err)
+	}
+	if _, ok := tmpl.(engineTemplate); ok && (opts.section != "" || len(opts.blocks) > 0) {
+		panic(fmt.Errorf("template %s is built by an Engine and can only be rendered as a whole", name))
+	}
+	return r.render(name, tmpl, data, opts, cacheKey)
+}
+
+// render returns the render of tmpl, the template registered under name, as
+// configured by the renderer and opts. A non-empty cacheKey keeps the output
+// in the output cache.
+func (r *registry) render(
+	name string,
+	tmpl executor,
+	data interface{},
+	opts instanceOptions,
+	cacheKey string,
+) render.Render {
+	html, isHTML := tmpl.(*template.Template)
+	minify := r.opts.autoMinify && !gin.IsDebugging()
+	buffered := opts.timeout > 0 || r.opts.prettyHTML || minify || len(r.opts.postProcessors) > 0 ||
+		!isHTML || cacheKey != "" || len(opts.blocks) > 0 || opts.postProcess != nil || r.opts.serverTiming
+	if buffered {
+		rr := bufferedRender{
+			template:     tmpl,
+			name:         opts.section,
+			blocks:       opts.blocks,
+			data:         data,
+			timeout:      opts.timeout,
+			pretty:       r.opts.prettyHTML,
+			minify:       minify,
+			postProcess:  chainProcessors(r.postProcessor(name), opts.postProcess),
+			failed:       func(err error) { r.recentErrors.add(name, err) },
+			contentType:  r.contentType(name),
+			serverTiming: r.opts.serverTiming,
+		}
+		if cacheKey != "" {
+			rr.store = func(out []byte) { r.outputCache.put(cacheKey, out) }
+		}
+		return r.wrapWriter(rr)
+	}
+	return r.wrapWriter(r.bufio(render.HTML{
+		Template: html,
+		Name:     opts.section,
+		Data:     data,
+	}))
+}
@@ -0,0 +1,236 @@
+package multitemplate
+
+import "fmt"
+
+// ReloadAll implements Render.ReloadAll and DynamicRender.ReloadAll.
+func (r *registry) ReloadAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reload()
+}
+
+// reload rebuilds the kept templates. r.mu must be held for writing.
+func (r *registry) reload() error {
+	built := make(map[*templateBuilder]*templateBuilder, len(r.builders))
+	for _, name := range sortedNames(r.builders) {
+		builder := r.builders[name]
+		if builder.buildType == templateType || r.dynamic && !builder.pinned {
+			continue
+		}
+		rebuilt, err := r.rebuild(builder)
+		if err != nil {
+			return fmt.Errorf("reload template %s: %w", name, err)
+		}
+		built[builder] = rebuilt
+	}
+
+	var locales map[string]map[string]*templateBuilder
+	if !r.dynamic && r.locales != nil {
+		locales = make(map[string]map[string]*templateBuilder, len(r.locales))
+		for _, name := range sortedNames(r.locales) {
+			variants := make(map[string]*templateBuilder, len(r.locales[name]))
+			for _, locale := range sortedNames(r.locales[name]) {
+				rebuilt, err := r.rebuild(r.locales[name][locale])
+				if err != nil {
+					return fmt.Errorf("reload template %s locale %s: %w", name, locale, err)
+				}
+				variants[locale] = rebuilt
+			}
+			locales[name] = variants
+		}
+	}
+
+	for name, builder := range r.builders {
+		if rebuilt, ok := built[builder]; ok {
+			r.builders[name] = rebuilt
+		}
+	}
+	if locales != nil {
+		r.locales = locales
+	}
+	r.gen.Add(1)
+	if r.outputCache != nil {
+		r.outputCache.invalidate("")
+	}
+	return nil
+}
+
+// rebuild returns a copy of builder with the template rebuilt, or builder
+// itself if the checksum of its sources did not change.
+func (r *registry) rebuild(builder *templateBuilder) (*templateBuilder, error) {
+	if kept := builder.keptSum(); r.opts.checksumReload && kept != "" {
+		if sum, err := builder.checksum(); err == nil && sum == kept {
+			return builder, nil
+		}
+	}
+	rebuilt := *builder
+	if err := rebuilt.keep(); err != nil {
+		return nil, err
+	}
+	return &rebuilt, nil
+}
+
+// SetVersion implements Render.SetVersion and DynamicRender.SetVersion.
+func (r *registry) SetVersion(v string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.version = v
+}
+
+// Version implements Render.Version and DynamicRender.Version.
+func (r *registry) Version() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version
+}
+
+// syncVersion reloads the templates if the version changed since they were built.
+func (r *registry) syncVersion() error {
+	r.mu.RLock()
+	stale := r.version != r.builtVersion
+	r.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.version == r.builtVersion {
+		return nil
+	}
+	r.builtVersion = r.version
+	return r.reload()
+}
+
+// StageAndSwap implements Render.StageAndSwap and DynamicRender.StageAndSwap.
+func (r *registry) StageAndSwap(build func(r Renderer) error) (err error) {
+	r.mu.RLock()
+	staging := &registry{
+		builders: make(map[string]*templateBuilder),
+		dynamic:  r.dynamic,
+		opts:     r.opts,
+	}
+	r.mu.RUnlock()
+
+	var stage Renderer = bind(make(Render), staging)
+	if r.dynamic {
+		stage = bind(make(DynamicRender), staging)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("stage templates: %v", p)
+		}
+	}()
+	if err := build(stage); err != nil {
+		return err
+	}
+	if err := staging.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range r.builders {
+		delete(r.builders, name)
+	}
+	for name, builder := range staging.builders {
+		builder.settings = &r.opts
+		r.builders[name] = builder
+	}
+	for _, variants := range staging.locales {
+		for _, builder := range variants {
+			builder.settings = &r.opts
+		}
+	}
+	r.gen.Add(1)
+	r.opts = staging.opts
+	r.locales = staging.locales
+	r.errorPages = staging.errorPages
+	r.cacheable = staging.cacheable
+	r.timeouts = staging.timeouts
+	r.cachePolicies = staging.cachePolicies
+	r.shells = staging.shells
+	if r.outputCache != nil {
+		r.outputCache.invalidate("")
+	}
+	return nil
+}
+
+// UpdateString implements Render.UpdateString and DynamicRender.UpdateString.
+func (r *registry) UpdateString(name, body string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	builder, ok := r.builders[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	updated := *builder
+	switch builder.buildType {
+	case stringTemplateType:
+		updated.templateString = body
+	case stringFuncTemplateType:
+		if len(builder.templateStrings) > 1 {
+			return fmt.Errorf("template %s is built from %d strings and cannot be updated", name,
+				len(builder.templateStrings))
+		}
+		updated.templateStrings = []string{body}
+	default:
+		return fmt.Errorf("template %s is not a string template", name)
+	}
+	if err := updated.keep(); err != nil {
+		return fmt.Errorf("update template %s: %w", name, err)
+	}
+
+	r.builders[name] = &updated
+	r.gen.Add(1)
+	if r.outputCache != nil {
+		r.outputCache.invalidate(name)
+	}
+	return nil
+}
+
+// pin implements DynamicRender.Pin.
+func (r *registry) pin(names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pinned := make(map[string]*templateBuilder, len(names))
+	for _, name := range names {
+		builder, ok := r.builders[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+		}
+		kept := *builder
+		if err := kept.keep(); err != nil {
+			return fmt.Errorf("pin template %s: %w", name, err)
+		}
+		kept.pinned = true
+		pinned[name] = &kept
+	}
+
+	for name, builder := range pinned {
+		r.builders[name] = builder
+	}
+	r.gen.Add(1)
+	return nil
+}
+
+// flushCache implements DynamicRender.FlushCache.
+func (r *registry) flushCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, builder := range r.builders {
+		if builder.pinned {
+			unpinned := *builder
+			unpinned.pinned = false
+			r.builders[name] = &unpinned
+		}
+	}
+	r.gen.Add(1)
+	if r.outputCache != nil {
+		r.outputCache.invalidate("")
+	}
+}
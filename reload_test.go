@@ -0,0 +1,184 @@
+package multitemplate
+
+import (
+	"errors"
+	"html/template"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetVersion(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+
+	r := New()
+	r.AddFromFiles("index", file)
+	assert.NoError(t, os.WriteFile(file, []byte("v2"), 0o600))
+
+	w, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", w.Body.String())
+
+	r.SetVersion("2")
+	assert.Equal(t, "2", r.Version())
+	w, err = renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", w.Body.String())
+
+	assert.NoError(t, os.WriteFile(file, []byte("{{ broken"), 0o600))
+	r.SetVersion("3")
+	_, err = r.Build("index")
+	assert.Error(t, err)
+	w, err = renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", w.Body.String())
+}
+
+func TestReloadAll(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+
+	r := New()
+	r.AddFromFiles("index", file)
+	r.AddFromString("string", "string")
+	assert.NoError(t, os.WriteFile(file, []byte("v2"), 0o600))
+	assert.NoError(t, r.ReloadAll())
+
+	w, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", w.Body.String())
+	assert.NoError(t, NewDynamic().ReloadAll())
+}
+
+func TestChecksumReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.html")
+	assert.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+
+	r := New(WithChecksumReload())
+	r.AddFromFiles("index", file)
+	r.AddFromString("string", "string")
+	before := r["index"]
+
+	assert.NoError(t, os.WriteFile(file, []byte("v1"), 0o600))
+	changed, err := r.HasChanged("index")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoError(t, r.ReloadAll())
+	assert.Same(t, before, r["index"])
+
+	assert.NoError(t, os.WriteFile(file, []byte("v2"), 0o600))
+	changed, err = r.HasChanged("index")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, r.ReloadAll())
+	w, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", w.Body.String())
+
+	changed, err = r.HasChanged("string")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	_, err = r.HasChanged("missing")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	dynamic := NewDynamic()
+	dynamic.AddFromFiles("index", file)
+	changed, err = dynamic.HasChanged("index")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	// without checksum reload no checksum is kept to compare with
+	plain := New()
+	plain.AddFromFiles("index", file)
+	assert.Empty(t, plain.registry().builders["index"].sum)
+	changed, err = plain.HasChanged("index")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
+
+func TestStageAndSwap(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "v1")
+
+	err := r.StageAndSwap(func(stage Renderer) error {
+		stage.AddFromString("index", "v2")
+		stage.AddFromString("broken", `{{ template "missing" }}`)
+		return nil
+	})
+	assert.Error(t, err)
+
+	err = r.StageAndSwap(func(stage Renderer) error {
+		stage.AddFromString("index", "{{ broken")
+		return nil
+	})
+	assert.Error(t, err)
+
+	errBuild := errors.New("build failed")
+	assert.ErrorIs(t, r.StageAndSwap(func(Renderer) error { return errBuild }), errBuild)
+
+	w, err := renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", w.Body.String())
+
+	assert.NoError(t, r.StageAndSwap(func(stage Renderer) error {
+		stage.AddFromString("index", "v2")
+		stage.AddFromString("about", "about")
+		return nil
+	}))
+	w, err = renderResponse(r, "index", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", w.Body.String())
+	assert.Equal(t, []string{"about", "index"}, r.Names())
+}
+
+func TestUpdateString(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromString("index", "Hello {{ .name }}")
+		r.AddFromFiles("files", "tests/base.html", "tests/article.html")
+
+		assert.NoError(t, r.UpdateString("index", "Welcome {{ .name }}"))
+		w, err := renderResponse(r, "index", map[string]string{"name": "Gin"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome Gin", w.Body.String())
+
+		assert.Error(t, r.UpdateString("index", "{{ .name "))
+		w, err = renderResponse(r, "index", map[string]string{"name": "Gin"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome Gin", w.Body.String())
+
+		assert.ErrorIs(t, r.UpdateString("missing", ""), ErrTemplateNotFound)
+		assert.EqualError(t, r.UpdateString("files", ""), "template files is not a string template")
+
+		r.AddFromStringsFuncs("page", template.FuncMap{}, `{{ template "content" . }}`, `{{ define "content" }}v1{{ end }}`)
+		assert.EqualError(t, r.UpdateString("page", "v2"), "template page is built from 2 strings and cannot be updated")
+		w, err = renderResponse(r, "page", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", w.Body.String())
+	}
+}
+
+func TestStageAndSwapSettings(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "v1")
+	r.SetCachePolicy("index", "no-cache")
+
+	assert.NoError(t, r.StageAndSwap(func(stage Renderer) error {
+		stage.AddFromString("index", "v2")
+		staged := stage.(testRenderer)
+		staged.SetCachePolicy("index", "max-age=60")
+		staged.SetTimeout("index", time.Second)
+		staged.SetCacheable("index")
+		staged.SetDebugFuncs(template.FuncMap{"dump": func(interface{}) string { return "" }})
+		return nil
+	}))
+
+	reg := r.registry()
+	assert.Equal(t, map[string]string{"index": "max-age=60"}, reg.cachePolicies)
+	assert.Equal(t, time.Second, reg.timeouts["index"])
+	assert.True(t, reg.cacheable["index"])
+	assert.Contains(t, reg.opts.debugFuncs, "dump")
+	assert.Same(t, &reg.opts, reg.builders["index"].settings)
+}
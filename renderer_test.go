@@ -0,0 +1,104 @@
+package multitemplate
+
+import (
+	"hash"
+	"html/template"
+	"io"
+	"io/fs"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// testRenderer is implemented by both Render and DynamicRender, so tests can
+// run against either kind of renderer.
+type testRenderer interface {
+	Renderer
+	AddIf(cond bool, name string, files ...string) *template.Template
+	AddErrorPage(status int, name string, files ...string) *template.Template
+	AddFromManifest(manifest map[string][]string)
+	AddFromZip(name, zipPath string, files ...string) *template.Template
+	AddFromZipGlob(name, zipPath, pattern string) *template.Template
+	AddFromLayeredFS(name string, layers []fs.FS, files ...string) *template.Template
+	AddAllFromFS(fsys fs.FS, root string)
+	AddAllFromFSFuncs(fsys fs.FS, root string, funcMap template.FuncMap)
+	AddFromDirFuncs(root, pattern string, funcMap template.FuncMap)
+	AddFromFSFuncsGlob(name string, funcMap template.FuncMap, fsys fs.FS, pattern string) *template.Template
+	AddFromStringDelims(name, left, right, templateString string) *template.Template
+	AddFromStringNamed(key, templateName, templateString string) *template.Template
+	AddFromFilesEngine(name string, newEngine func() Engine, files ...string)
+	AddTree(name string, tree *parse.Tree) (*template.Template, error)
+	AddLocalized(name string, filesByLocale map[string]string)
+	AddLazy(name string, factory func() (*template.Template, error))
+	AddFromFSGlob(name string, fsys fs.FS, pattern string)
+	AddTrustedFromString(name, templateString string) *texttemplate.Template
+	AddTrustedFromFiles(name string, files ...string) *texttemplate.Template
+	AddTrustedFromFS(name string, fsys fs.FS, files ...string) *texttemplate.Template
+	AddTextFromString(name, templateString string) *texttemplate.Template
+	AddTextFromFiles(name string, files ...string) *texttemplate.Template
+	AddFromFileSections(name, file string) *template.Template
+	InstanceSection(name, section string, data interface{}) render.Render
+	InstanceBlocks(name string, blocks []string, data interface{}) render.Render
+	InstanceOOB(name string, blocks map[string]interface{}) render.Render
+	InstanceCtx(c *gin.Context, name string, data interface{}) render.Render
+	InstanceWith(tmpl *template.Template, data interface{}) render.Render
+	InstanceFuncs(name string, funcMap template.FuncMap, data interface{}) render.Render
+	InstanceETag(c *gin.Context, name string, data interface{}, etag string) render.Render
+	InstanceMerge(name string, base interface{}, extra map[string]interface{}) render.Render
+	InstanceMulti(name string, datas ...interface{}) render.Render
+	InstanceHydrate(name string, data interface{}) render.Render
+	InstanceEmpty(headers map[string]string) render.Render
+	InstanceError(status int, data interface{}) render.Render
+	CacheShell(shellName, name string, data interface{}) error
+	InstanceShell(shellName, name string, data interface{}) render.Render
+	InstanceLocale(name, locale string, data interface{}) render.Render
+	InstanceTimeout(name string, data interface{}, d time.Duration) render.Render
+	SetTimeout(name string, d time.Duration)
+	SetCachePolicy(name, policy string)
+	InstanceStream(name string, data interface{}, trailer string, h hash.Hash) render.Render
+	InstanceList(outer, row string, items []interface{}, data interface{}) render.Render
+	BatchRender(name string, items []interface{}, sink func(i int, r io.Reader) error) error
+	Build(name string) (*template.Template, error)
+	Merge(other Renderer) error
+	ReloadAll() error
+	UpdateString(name, body string) error
+	StageAndSwap(build func(r Renderer) error) error
+	Validate() error
+	ValidateReport() (ok bool, report string)
+	ValidateIncludes() error
+	DryRun(name string, data interface{}) error
+	Meta(name string) (map[string]interface{}, error)
+	HasChanged(name string) (bool, error)
+	SetVersion(v string)
+	Version() string
+	SetCommonFiles(files ...string)
+	SetDebugFuncs(funcMap template.FuncMap)
+	UseBundle(b FuncBundle)
+	SetSRIManifest(manifest map[string]string)
+	SetResolver(fn func(name string, c *gin.Context) string)
+	SetFlagProvider(fn func(name string, c *gin.Context) bool)
+	TemplatesForFile(path string) []string
+	DefinedTemplates(name string) ([]string, error)
+	DumpJSON() ([]byte, error)
+	Names() []string
+	MustNames(expected ...string)
+	AllDefinedTemplates() map[string][]string
+	ForEach(fn func(name string, tmpl *template.Template) bool)
+	Snapshot() *Snapshot
+	Exists(name string) bool
+	Funcs(name string) template.FuncMap
+	UsedFuncs(name string) ([]string, error)
+	SetCacheable(names ...string)
+	InvalidateOutput(name string)
+	RenderErrorPage(c *gin.Context, name string, err error)
+	RenderEmail(name string, data interface{}) (string, error)
+	RenderHash(name string, data interface{}) (string, error)
+	DiffRenders(name string, data interface{}, oldTemplate *template.Template) (bool, error)
+	RecentErrors() []RenderError
+	RenderToWriter(w io.Writer, name string, data interface{}) error
+	ExportStatic(dir string, jobs []ExportJob) error
+	ExportSources() (map[string]SourceBundle, error)
+}
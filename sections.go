@@ -0,0 +1,85 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// Section markers recognized by AddFromFileSections, e.g.
+//
+//	<!-- section: intro -->
+//	...
+//	<!-- endsection -->
+var (
+	sectionStartMarker = regexp.MustCompile(`<!--\s*section:\s*([\w.-]+)\s*-->`)
+	sectionEndMarker   = regexp.MustCompile(`<!--\s*endsection\s*-->`)
+)
+
+// expandSections turns every marked section of src into a block, so the
+// section still renders in place and can also be executed on its own.
+func expandSections(src string, options TemplateOptions) string {
+	left, right := options.LeftDelimiter, options.RightDelimiter
+	src = sectionStartMarker.ReplaceAllString(src, left+`block "$1" .`+right)
+	return sectionEndMarker.ReplaceAllString(src, left+"end"+right)
+}
+
+// InstanceSection implements Render.InstanceSection and DynamicRender.InstanceSection.
+func (r *registry) InstanceSection(name, section string, data interface{}) render.Render {
+	name = r.resolve(nil, name)
+	return r.headers(name, r.instance(nil, name, data, instanceOptions{section: section}))
+}
+
+// InstanceBlocks implements Render.InstanceBlocks and DynamicRender.InstanceBlocks.
+func (r *registry) InstanceBlocks(name string, blocks []string, data interface{}) render.Render {
+	if len(blocks) == 0 {
+		panic("no blocks to render")
+	}
+	renderBlocks := make([]renderBlock, 0, len(blocks))
+	for _, block := range blocks {
+		renderBlocks = append(renderBlocks, renderBlock{name: block})
+	}
+	name = r.resolve(nil, name)
+	return r.headers(name, r.instance(nil, name, data, instanceOptions{blocks: renderBlocks, uncached: true}))
+}
+
+// renderBlock is an associated template executed by InstanceBlocks or InstanceOOB.
+type renderBlock struct {
+	name string
+	// oob blocks are executed with their own data and wrapped in an HTMX
+	// out-of-band swap element, see InstanceOOB.
+	oob  bool
+	data interface{}
+}
+
+// execute executes the block into w, with data unless the block has its own.
+func (b renderBlock) execute(w io.Writer, tmpl executor, data interface{}) error {
+	if !b.oob {
+		return tmpl.ExecuteTemplate(w, b.name, data)
+	}
+	id := template.HTMLEscapeString(b.name)
+	if _, err := fmt.Fprintf(w, `<div id="%s" hx-swap-oob="true">`, id); err != nil {
+		return err
+	}
+	if err := tmpl.ExecuteTemplate(w, b.name, b.data); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "</div>")
+	return err
+}
+
+// InstanceOOB implements Render.InstanceOOB and DynamicRender.InstanceOOB.
+func (r *registry) InstanceOOB(name string, blocks map[string]interface{}) render.Render {
+	if len(blocks) == 0 {
+		panic("no blocks to render")
+	}
+	renderBlocks := make([]renderBlock, 0, len(blocks))
+	for _, block := range sortedNames(blocks) {
+		renderBlocks = append(renderBlocks, renderBlock{name: block, oob: true, data: blocks[block]})
+	}
+	name = r.resolve(nil, name)
+	return r.headers(name, r.instance(nil, name, nil, instanceOptions{blocks: renderBlocks, uncached: true}))
+}
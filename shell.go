@@ -0,0 +1,75 @@
+package multitemplate
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// ShellPlaceholder marks where page content goes in a layout shell, see
+// CacheShell. Pass it in the layout data, e.g. gin.H{"content": ShellPlaceholder},
+// and output it where the content template should be inserted.
+const ShellPlaceholder template.HTML = "<!-- multitemplate:shell-content -->"
+
+// shell is the rendered output of a layout around its placeholder.
+type shell struct {
+	before, after []byte
+}
+
+// shellRender writes a cached shell around the output of a content render.
+type shellRender struct {
+	shell   shell
+	content render.Render
+}
+
+// Render (shellRender) writes the shell with the content render in its placeholder.
+func (r shellRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	if _, err := w.Write(r.shell.before); err != nil {
+		return err
+	}
+	if err := r.content.Render(w); err != nil {
+		return err
+	}
+	_, err := w.Write(r.shell.after)
+	return err
+}
+
+// WriteContentType (shellRender) writes the ContentType of the content render.
+func (r shellRender) WriteContentType(w http.ResponseWriter) {
+	r.content.WriteContentType(w)
+}
+
+// CacheShell implements Render.CacheShell and DynamicRender.CacheShell.
+func (r *registry) CacheShell(shellName, name string, data interface{}) error {
+	out, err := r.renderBytes(name, data)
+	if err != nil {
+		return err
+	}
+	before, after, found := bytes.Cut(out, []byte(ShellPlaceholder))
+	if !found {
+		return fmt.Errorf("template %s: shell output has no placeholder", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.shells == nil {
+		r.shells = make(map[string]shell)
+	}
+	r.shells[shellName] = shell{before: before, after: after}
+	return nil
+}
+
+// InstanceShell implements Render.InstanceShell and DynamicRender.InstanceShell.
+func (r *registry) InstanceShell(shellName, name string, data interface{}) render.Render {
+	r.mu.RLock()
+	s, ok := r.shells[shellName]
+	r.mu.RUnlock()
+	if !ok {
+		panic(fmt.Sprintf("shell %s is not cached", shellName))
+	}
+	return shellRender{shell: s, content: r.Instance(name, data)}
+}
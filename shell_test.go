@@ -0,0 +1,32 @@
+package multitemplate
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceShell(t *testing.T) {
+	r := New()
+	r.AddFromString("layout", "<nav>{{ .menu }}</nav><main>{{ .content }}</main>")
+	r.AddFromString("page", "<h1>{{ .title }}</h1>")
+	r.AddFromString("broken", "<nav>{{ .menu }}</nav>")
+
+	assert.NoError(t, r.CacheShell("main", "layout", gin.H{"menu": "home", "content": ShellPlaceholder}))
+	assert.Error(t, r.CacheShell("broken", "broken", gin.H{"menu": "home"}))
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceShell("main", "page", gin.H{"title": "<Hi>"}))
+	})
+
+	for i := 0; i < 2; i++ {
+		w := performRequest(router)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "<nav>home</nav><main><h1>&lt;Hi&gt;</h1></main>", w.Body.String())
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	}
+	assert.Panics(t, func() { r.InstanceShell("missing", "page", nil) })
+}
@@ -0,0 +1,139 @@
+package multitemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+)
+
+// Snapshot is a consistent, immutable view of the templates registered on a
+// renderer at the time Snapshot was called. Iterating and building from it
+// does not lock the renderer, so slow enumerations, e.g. DumpJSON, neither
+// block nor are affected by concurrent Add or ReloadAll calls.
+type Snapshot struct {
+	builders map[string]templateBuilder
+	dynamic  bool
+	// err is the error of the version reload preceding the snapshot, see SetVersion.
+	err error
+}
+
+// Snapshot implements Render.Snapshot and DynamicRender.Snapshot.
+func (r *registry) Snapshot() *Snapshot {
+	err := r.syncVersion()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// the builders use a copy of the settings, unaffected by later Set calls
+	settings := r.opts
+	builders := make(map[string]templateBuilder, len(r.builders))
+	for name, builder := range r.builders {
+		copied := *builder
+		if copied.settings != nil {
+			copied.settings = &settings
+		}
+		builders[name] = copied
+	}
+	return &Snapshot{builders: builders, dynamic: r.dynamic, err: err}
+}
+
+// Names returns the names of the templates in the snapshot, sorted.
+func (s *Snapshot) Names() []string {
+	return sortedNames(s.builders)
+}
+
+// Len returns the number of templates in the snapshot.
+func (s *Snapshot) Len() int {
+	return len(s.builders)
+}
+
+// Exists reports whether a template is registered under name in the snapshot.
+func (s *Snapshot) Exists(name string) bool {
+	_, ok := s.builders[name]
+	return ok
+}
+
+// Build returns the template registered under name in the snapshot, like
+// the Build method of the renderer.
+func (s *Snapshot) Build(name string) (*template.Template, error) {
+	built, err := s.build(name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, ok := built.(*template.Template)
+	if !ok {
+		return nil, fmt.Errorf("template %s is not an html/template template", name)
+	}
+	builder := s.builders[name]
+	if s.dynamic && !builder.pinned || builder.buildType == templateType {
+		return tmpl, nil
+	}
+	// the kept template is shared with the renderer, so hand out a copy of a
+	// never executed one the caller may execute, like registry.Build
+	base := builder.base
+	if base == nil {
+		if base, err = builder.buildExecutor(); err != nil {
+			return nil, err
+		}
+	}
+	return base.(*template.Template).Clone()
+}
+
+func (s *Snapshot) build(name string) (executor, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	builder, ok := s.builders[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	return builder.current(s.dynamic)
+}
+
+// ForEach builds every template of the snapshot, in name order, and calls fn
+// with its name and template until fn returns false, like the ForEach method
+// of the renderer.
+func (s *Snapshot) ForEach(fn func(name string, tmpl *template.Template) bool) {
+	for _, name := range s.Names() {
+		tmpl, _ := s.Build(name)
+		if !fn(name, tmpl) {
+			return
+		}
+	}
+}
+
+// DumpJSON serializes every template of the snapshot, like the DumpJSON
+// method of the renderer.
+func (s *Snapshot) DumpJSON() ([]byte, error) {
+	infos := make([]TemplateInfo, 0, len(s.builders))
+	for _, name := range s.Names() {
+		infos = append(infos, s.info(name))
+	}
+	return json.Marshal(infos)
+}
+
+// info describes the template registered under name.
+func (s *Snapshot) info(name string) TemplateInfo {
+	builder := s.builders[name]
+	info := TemplateInfo{
+		Name:    name,
+		Type:    builder.buildType.String(),
+		Files:   builder.files,
+		Glob:    builder.glob,
+		Trusted: builder.text,
+	}
+	for fn := range builder.funcs() {
+		info.Funcs = append(info.Funcs, fn)
+	}
+	sort.Strings(info.Funcs)
+
+	tmpl, err := s.build(name)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Defined = definedTemplates(tmpl)
+	sort.Strings(info.Defined)
+	return info
+}
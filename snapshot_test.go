@@ -0,0 +1,93 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromString("index", "Welcome to {{ .name }} template")
+		r.AddTrustedFromString("trusted", "Trusted {{ .name }}")
+
+		snapshot := r.Snapshot()
+		r.AddFromString("later", "Later")
+		r.SetDebugFuncs(template.FuncMap{"dump": func(interface{}) string { return "" }})
+
+		assert.Equal(t, []string{"index", "trusted"}, snapshot.Names())
+		assert.Equal(t, 2, snapshot.Len())
+		assert.True(t, snapshot.Exists("index"))
+		assert.False(t, snapshot.Exists("later"))
+
+		tmpl, err := snapshot.Build("index")
+		assert.NoError(t, err)
+		assert.Equal(t, "index", tmpl.Name())
+		_, err = snapshot.Build("trusted")
+		assert.EqualError(t, err, "template trusted is not an html/template template")
+		_, err = snapshot.Build("later")
+		assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+		var visited []string
+		snapshot.ForEach(func(name string, tmpl *template.Template) bool {
+			visited = append(visited, name)
+			return true
+		})
+		assert.Equal(t, []string{"index", "trusted"}, visited)
+
+		dump, err := snapshot.DumpJSON()
+		assert.NoError(t, err)
+		assert.NotContains(t, string(dump), "later")
+		assert.NotContains(t, string(dump), "dump")
+	}
+}
+
+func TestSnapshotConcurrentAdd(t *testing.T) {
+	r := NewDynamic()
+	for i := 0; i < 20; i++ {
+		r.AddFromString(fmt.Sprintf("page%02d", i), "Page")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 20; i < 40; i++ {
+			r.AddFromString(fmt.Sprintf("page%02d", i), "Page")
+		}
+	}()
+
+	snapshot := r.Snapshot()
+	count := 0
+	snapshot.ForEach(func(name string, tmpl *template.Template) bool {
+		assert.True(t, strings.HasPrefix(name, "page"))
+		assert.NotNil(t, tmpl)
+		count++
+		return true
+	})
+	wg.Wait()
+	assert.Equal(t, snapshot.Len(), count)
+	assert.Len(t, r.Names(), 40)
+}
+
+func TestSnapshotBuildClone(t *testing.T) {
+	r := New()
+	r.AddFromString("index", "Welcome to {{ .name }} template")
+
+	tmpl, err := r.Snapshot().Build("index")
+	assert.NoError(t, err)
+	assert.NotSame(t, r["index"], tmpl)
+	assert.NoError(t, tmpl.Execute(&strings.Builder{}, map[string]string{"name": "index"}))
+	_, err = tmpl.New("extra").Parse("Extra")
+	assert.Error(t, err)
+
+	// the renderer can still clone its template for the next render
+	r.SetDebugFuncs(template.FuncMap{"dump": func(interface{}) string { return "" }})
+	w, err := renderResponse(r, "index", map[string]string{"name": "index"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome to index template", w.Body.String())
+}
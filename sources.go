@@ -0,0 +1,329 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// templateSource is a single file backing a template builder.
+type templateSource struct {
+	// name is the template name the file is parsed under.
+	name string
+	// path is the resolved path of the file, relative to fsys if set.
+	path    string
+	fsys    fs.FS
+	content string
+	// common is set for the common files of the renderer.
+	common bool
+	// meta is the front matter split off the content, see WithFrontMatter.
+	meta map[string]interface{}
+}
+
+// errNoGlobMatches reports a glob pattern that matched no files. Relative
+// patterns are resolved against the working directory, which is included
+// because it usually differs from what the caller expected.
+func errNoGlobMatches(pattern string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "unknown"
+	}
+	return fmt.Errorf("glob pattern %q matches no files (working directory %s)", pattern, wd)
+}
+
+// resolveFiles returns the paths of the files backing a file, glob or fs.FS
+// builder, expanding glob patterns. Other builder types return nil.
+func (tb templateBuilder) resolveFiles() ([]string, error) {
+	switch tb.buildType {
+	case filesTemplateType, filesFuncTemplateType, sectionsTemplateType, engineTemplateType:
+		if len(tb.files) == 0 {
+			return nil, fmt.Errorf("template %s: no files named", tb.templateName)
+		}
+		return tb.files, nil
+	case globTemplateType:
+		files, err := filepath.Glob(tb.glob)
+		if err == nil && len(files) == 0 {
+			err = errNoGlobMatches(tb.glob)
+		}
+		return files, err
+	case fsTemplateType, fsFuncTemplateType, fsGlobTemplateType:
+		if tb.literal {
+			return tb.files, nil
+		}
+		var files []string
+		for _, pattern := range tb.files {
+			matches, err := fs.Glob(tb.fsys, pattern)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("template %s: pattern %q matches no files", tb.templateName, pattern)
+			}
+			files = append(files, matches...)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("template %s: no files named", tb.templateName)
+		}
+		return files, nil
+	default:
+		return nil, nil
+	}
+}
+
+// withCommonFiles returns files preceded by the common files set on the
+// renderer, for builders that parse files from the OS file system.
+func (tb templateBuilder) withCommonFiles(files []string) []string {
+	if tb.settings == nil || len(tb.settings.commonFiles) == 0 {
+		return files
+	}
+	switch tb.buildType {
+	case filesTemplateType, filesFuncTemplateType, globTemplateType, sectionsTemplateType:
+		common := tb.settings.commonFiles
+		return append(append(make([]string, 0, len(common)+len(files)), common...), files...)
+	default:
+		return files
+	}
+}
+
+// sourceName returns the template name file is parsed under. Like
+// template.ParseFiles it is the base name of the file, unless a base
+// directory is set: then it is the slash separated path relative to that
+// directory, for OS and fs.FS files alike.
+func (tb templateBuilder) sourceName(file string) string {
+	baseDir := ""
+	if tb.settings != nil {
+		baseDir = tb.settings.baseDir
+	}
+
+	if tb.fsys != nil {
+		if baseDir != "" {
+			base := path.Clean(filepath.ToSlash(baseDir))
+			if base == "." {
+				return path.Clean(file)
+			}
+			if rel, ok := strings.CutPrefix(path.Clean(file), base+"/"); ok {
+				return rel
+			}
+		}
+		return path.Base(file)
+	}
+
+	if baseDir != "" {
+		if rel, err := filepath.Rel(baseDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.Base(file)
+}
+
+// sources resolves and reads the files backing a file, glob or fs.FS builder,
+// preceded by the common files of the renderer.
+// Other builder types have no backing files and return nil.
+func (tb templateBuilder) sources() ([]templateSource, error) {
+	if tb.loaded != nil {
+		return tb.loaded, nil
+	}
+	if tb.buildType == zipTemplateType {
+		return tb.zipSources()
+	}
+
+	files, err := tb.resolveFiles()
+	if err != nil {
+		return nil, err
+	}
+	all := tb.withCommonFiles(files)
+	common := len(all) - len(files)
+
+	sources := make([]templateSource, 0, len(all))
+	for i, file := range all {
+		source := templateSource{
+			name:   tb.sourceName(file),
+			path:   file,
+			fsys:   tb.fsys,
+			common: i < common,
+		}
+		var b []byte
+		var err error
+		if tb.fsys != nil {
+			b, err = fs.ReadFile(tb.fsys, file)
+		} else {
+			b, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if source.content, source.meta, err = tb.prepare(source.name, string(b)); err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// texts returns the template texts the builder parses: its sources, or its
+// strings for string templates, after the source transform of the renderer.
+func (tb templateBuilder) texts() ([]templateSource, error) {
+	switch tb.buildType {
+	case stringTemplateType:
+		content, meta, err := tb.prepare(tb.templateName, tb.templateString)
+		if err != nil {
+			return nil, err
+		}
+		return []templateSource{{name: tb.templateName, content: content, meta: meta}}, nil
+	case stringFuncTemplateType:
+		texts := make([]templateSource, 0, len(tb.templateStrings))
+		for _, ts := range tb.templateStrings {
+			content, meta, err := tb.prepare(tb.templateName, ts)
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, templateSource{name: tb.templateName, content: content, meta: meta})
+		}
+		return texts, nil
+	default:
+		return tb.sources()
+	}
+}
+
+// prepare turns the text of the template (or file) name into the content to
+// parse: it splits off the front matter, if enabled, and applies the source
+// transform of the renderer.
+func (tb templateBuilder) prepare(name, src string) (string, map[string]interface{}, error) {
+	if tb.settings == nil {
+		return src, nil, nil
+	}
+	var meta map[string]interface{}
+	if tb.settings.frontMatter {
+		var err error
+		if meta, src, err = splitFrontMatter(src); err != nil {
+			return "", nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if tb.settings.sourceTransform != nil {
+		var err error
+		if src, err = tb.settings.sourceTransform(name, src); err != nil {
+			return "", nil, err
+		}
+	}
+	return src, meta, nil
+}
+
+// rootName returns the name of the template executed by default: like
+// template.ParseFiles, the name of the first file of the builder.
+func rootName(sources []templateSource) (string, bool) {
+	for _, source := range sources {
+		if !source.common {
+			return source.name, true
+		}
+	}
+	return "", false
+}
+
+// parseSources parses sources into a new template named after the first
+// file of the builder, the same way template.ParseFiles does.
+func (tb templateBuilder) parseSources(sources []templateSource) (*template.Template, error) {
+	return parseSourceSet(tb, sources, tb.newTemplate)
+}
+
+// templateSet is a template of html/template or text/template.
+type templateSet[T any] interface {
+	Name() string
+	New(name string) T
+	Parse(text string) (T, error)
+}
+
+// parseSourceSet parses sources into a new template set allocated by
+// newTemplate, see parseSources, after checking them for duplicate defines
+// if the renderer asks for it.
+func parseSourceSet[T templateSet[T]](
+	tb templateBuilder,
+	sources []templateSource,
+	newTemplate func(name string) T,
+) (T, error) {
+	var zero T
+	if tb.settings != nil && tb.settings.checkDefines {
+		if err := tb.checkDuplicateDefines(sources); err != nil {
+			return zero, err
+		}
+	}
+	root, ok := rootName(sources)
+	if !ok {
+		return zero, fmt.Errorf("template %s: no files named", tb.templateName)
+	}
+
+	tmpl := newTemplate(root)
+	for _, source := range sources {
+		t := tmpl
+		if source.name != tmpl.Name() {
+			t = tmpl.New(source.name)
+		}
+		if _, err := t.Parse(source.content); err != nil {
+			return zero, err
+		}
+	}
+	return tmpl, nil
+}
+
+// walkFS returns the regular files below root in fsys, keyed by their slash
+// separated path relative to root.
+func walkFS(fsys fs.FS, root string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		name := file
+		if root != "." {
+			name = strings.TrimPrefix(file, path.Clean(root)+"/")
+		}
+		files[name] = file
+		return nil
+	})
+	return files, err
+}
+
+// checkDuplicateDefines parses every source file on its own and returns an
+// error listing the template names defined in more than one file.
+func (tb templateBuilder) checkDuplicateDefines(sources []templateSource) error {
+	definedIn := make(map[string][]string)
+	for _, source := range sources {
+		tree := parse.New(source.name)
+		tree.Mode = parse.SkipFuncCheck
+		treeSet := make(map[string]*parse.Tree)
+		if _, err := tree.Parse(
+			source.content,
+			tb.options.LeftDelimiter,
+			tb.options.RightDelimiter,
+			treeSet,
+		); err != nil {
+			return err
+		}
+		for name, t := range treeSet {
+			if t.Root != nil && !parse.IsEmptyTree(t.Root) {
+				definedIn[name] = append(definedIn[name], source.path)
+			}
+		}
+	}
+
+	var conflicts []string
+	for name, paths := range definedIn {
+		if len(paths) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%q in %s", name, strings.Join(paths, ", ")))
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+	return fmt.Errorf("template %s defines duplicate templates: %s",
+		tb.templateName, strings.Join(conflicts, "; "))
+}
@@ -0,0 +1,87 @@
+package multitemplate
+
+import (
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// streamRender executes the template straight into the response while
+// hashing the body, and sends the hex encoded sum as an HTTP trailer.
+type streamRender struct {
+	template executor
+	// name of the associated template to execute instead of the root one.
+	name    string
+	data    interface{}
+	trailer string
+	hash    hash.Hash
+	// contentType replaces the HTML Content-Type if set.
+	contentType string
+}
+
+var _ render.Render = streamRender{}
+
+// Render (streamRender) executes the template into the response body and sets the trailer.
+func (r streamRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	// announce the trailer before the body is written
+	w.Header().Set("Trailer", r.trailer)
+
+	out := io.MultiWriter(w, r.hash)
+	var err error
+	if r.name == "" {
+		err = r.template.Execute(out, r.data)
+	} else {
+		err = r.template.ExecuteTemplate(out, r.name, r.data)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set(http.TrailerPrefix+r.trailer, hex.EncodeToString(r.hash.Sum(nil)))
+	return nil
+}
+
+// WriteContentType (streamRender) writes HTML ContentType.
+func (r streamRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		if r.contentType != "" {
+			header["Content-Type"] = []string{r.contentType}
+		} else {
+			header["Content-Type"] = htmlContentType
+		}
+	}
+}
+
+// InstanceStream implements Render.InstanceStream and DynamicRender.InstanceStream.
+func (r *registry) InstanceStream(name string, data interface{}, trailer string, h hash.Hash) render.Render {
+	name = r.resolve(nil, name)
+	rr := r.instance(nil, name, data, instanceOptions{uncached: true})
+	return r.headers(name, toStream(rr, trailer, h, r.contentType(name)))
+}
+
+// toStream turns a template render into a streamRender. Other renders, e.g.
+// returned by WithRecover or WithMissingAsEmpty, are returned as they are.
+func toStream(rr render.Render, trailer string, h hash.Hash, contentType string) render.Render {
+	stream := streamRender{trailer: trailer, hash: h, contentType: contentType}
+	switch rr := rr.(type) {
+	case render.HTML:
+		stream.template, stream.name, stream.data = rr.Template, rr.Name, rr.Data
+		return stream
+	case bufferedRender:
+		stream.template, stream.name, stream.data = rr.template, rr.name, rr.data
+		return stream
+	case bufioRender:
+		rr.wrapped = toStream(rr.wrapped, trailer, h, contentType)
+		return rr
+	case writerRender:
+		rr.wrapped = toStream(rr.wrapped, trailer, h, contentType)
+		return rr
+	default:
+		return rr
+	}
+}
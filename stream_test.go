@@ -0,0 +1,44 @@
+package multitemplate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceStream(t *testing.T) {
+	r := createFromStringDynamic().(testRenderer)
+
+	w := httptest.NewRecorder()
+	err := r.InstanceStream("index", gin.H{"name": "stream"}, "Checksum", sha256.New()).Render(w)
+	assert.NoError(t, err)
+
+	res := w.Result()
+	defer res.Body.Close()
+	sum := sha256.Sum256([]byte("Welcome to stream template"))
+	assert.Equal(t, "Welcome to stream template", w.Body.String())
+	assert.Equal(t, hex.EncodeToString(sum[:]), res.Trailer.Get("Checksum"))
+	assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+	assert.Equal(t, "Checksum", res.Header.Get("Trailer"))
+	assert.Equal(t, "text/html; charset=utf-8", res.Header.Get("Content-Type"))
+}
+
+func TestInstanceStreamText(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddTextFromString("robots", "User-agent: {{ .agent }}")
+
+		w := httptest.NewRecorder()
+		err := r.InstanceStream("robots", gin.H{"agent": "*"}, "Checksum", sha256.New()).Render(w)
+		assert.NoError(t, err)
+
+		res := w.Result()
+		defer res.Body.Close()
+		assert.Equal(t, "User-agent: *", w.Body.String())
+		assert.Equal(t, "text/plain; charset=utf-8", res.Header.Get("Content-Type"))
+		assert.NotEmpty(t, res.Trailer.Get("Checksum"))
+	}
+}
@@ -0,0 +1,193 @@
+package multitemplate
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	texttemplate "text/template"
+)
+
+// executor is implemented by *html/template.Template and *text/template.Template.
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// buildExecutor builds the template with its Engine, with text/template for
+// trusted templates and with html/template otherwise.
+func (tb templateBuilder) buildExecutor() (executor, error) {
+	if tb.engine != nil {
+		return tb.buildEngine()
+	}
+	if tb.text {
+		tmpl, err := tb.buildText()
+		if err != nil {
+			return nil, err
+		}
+		return tmpl, nil
+	}
+
+	tmpl, err := tb.build()
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// keep builds the template and keeps it on the builder, as static renderers
+// do, along with the checksum of its sources if WithChecksumReload is set.
+// The sources are read once for the checksum, the front matter and the build.
+func (tb *templateBuilder) keep() error {
+	if tb.buildType == fsGlobTemplateType {
+		// the sources are read on first use, see AddFromFSGlob
+		tb.sum = ""
+		return tb.keepTemplate()
+	}
+
+	sources, err := tb.sources()
+	if err != nil {
+		return err
+	}
+	tb.loaded = sources
+	defer func() { tb.loaded = nil }()
+
+	tb.sum = ""
+	if tb.settings != nil && tb.settings.checksumReload {
+		tb.sum = sourcesChecksum(sources)
+	}
+	if tb.settings != nil && tb.settings.frontMatter {
+		if tb.meta, err = tb.frontMatter(); err != nil {
+			return err
+		}
+	}
+	return tb.keepTemplate()
+}
+
+func (tb *templateBuilder) keepTemplate() error {
+	tb.base = nil
+	if tb.engine != nil {
+		tmpl, err := tb.buildEngine()
+		if err != nil {
+			return err
+		}
+		tb.engineTmpl = tmpl
+		return nil
+	}
+	if tb.text {
+		tmpl, err := tb.buildText()
+		if err != nil {
+			return err
+		}
+		tb.textTmpl = tmpl
+		return nil
+	}
+	if tb.buildType == lazyTemplateType {
+		tb.lazy = &lazyTemplate{factory: tb.lazy.factory}
+		return nil
+	}
+	if tb.buildType == fsGlobTemplateType {
+		tb.lazy = newFSGlobTemplate(*tb)
+		return nil
+	}
+
+	tmpl, err := tb.build()
+	if err != nil {
+		return err
+	}
+	tb.tmpl = tmpl
+	return nil
+}
+
+// kept returns the template kept on the builder.
+func (tb templateBuilder) kept() executor {
+	if tb.engine != nil {
+		return tb.engineTmpl
+	}
+	if tb.text {
+		return tb.textTmpl
+	}
+	return tb.tmpl
+}
+
+// buildText builds a trusted template with text/template, which does not
+// escape anything.
+func (tb templateBuilder) buildText() (*texttemplate.Template, error) {
+	tmpl, err := tb.parseText()
+	if err != nil {
+		if checkErr := tb.checkParseError(); checkErr != nil {
+			return nil, checkErr
+		}
+		return nil, newTemplateError(err)
+	}
+	if err := tb.checkParsed(tmpl); err != nil {
+		return nil, err
+	}
+	tb.markMissing(tmpl)
+	if err := tb.limitDepth(tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// newTextTemplate works like newTemplate for text/template templates.
+func (tb templateBuilder) newTextTemplate(name string) *texttemplate.Template {
+	return texttemplate.New(name).
+		Delims(tb.options.LeftDelimiter, tb.options.RightDelimiter).
+		Funcs(predefinedFuncs).
+		Funcs(tb.funcs())
+}
+
+func (tb templateBuilder) parseText() (*texttemplate.Template, error) {
+	switch tb.buildType {
+	case stringTemplateType:
+		texts, err := tb.texts()
+		if err != nil {
+			return nil, err
+		}
+		return tb.newTextTemplate(tb.templateName).Parse(texts[0].content)
+	case filesTemplateType, fsTemplateType:
+		sources, err := tb.sources()
+		if err != nil {
+			return nil, err
+		}
+		return parseSourceSet(tb, sources, tb.newTextTemplate)
+	default:
+		return nil, fmt.Errorf("template %s: %s templates cannot be parsed as text", tb.templateName, tb.buildType)
+	}
+}
+
+// definedTemplates returns the names of all templates defined in the set of tmpl.
+func definedTemplates(tmpl executor) []string {
+	var names []string
+	switch t := tmpl.(type) {
+	case *template.Template:
+		for _, defined := range t.Templates() {
+			names = append(names, defined.Name())
+		}
+	case *texttemplate.Template:
+		for _, defined := range t.Templates() {
+			names = append(names, defined.Name())
+		}
+	}
+	return names
+}
+
+// cloneWithFuncs returns a clone of tmpl with funcMap bound.
+func cloneWithFuncs(tmpl executor, funcMap template.FuncMap) (executor, error) {
+	switch t := tmpl.(type) {
+	case *template.Template:
+		clone, err := t.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return clone.Funcs(funcMap), nil
+	case *texttemplate.Template:
+		clone, err := t.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return clone.Funcs(funcMap), nil
+	default:
+		return tmpl, nil
+	}
+}
@@ -0,0 +1,89 @@
+package multitemplate
+
+import (
+	"html/template"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTrusted(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddTrustedFromString("snippet", "<div>{{.}}</div>")
+		r.AddTrustedFromFS("page", os.DirFS("."), "tests/base.html", "tests/article.html")
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "snippet", "<b>cms</b>")
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "<div><b>cms</b></div>", w.Body.String())
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+		_, err := r.Build("snippet")
+		assert.Error(t, err)
+
+		names, err := r.DefinedTemplates("page")
+		assert.NoError(t, err)
+		assert.Contains(t, names, "base.html")
+	}
+}
+
+func TestAddTrustedWithContextFuncs(t *testing.T) {
+	r := NewDynamic(WithContextFuncs(func(c *gin.Context) template.FuncMap {
+		return template.FuncMap{"path": func() string {
+			if c == nil {
+				return ""
+			}
+			return c.Request.URL.Path
+		}}
+	}))
+	r.AddTrustedFromString("snippet", "<p>{{path}}</p>")
+
+	router := gin.New()
+	router.HTMLRender = r
+	router.GET("/", func(c *gin.Context) {
+		c.Render(200, r.InstanceCtx(c, "snippet", nil))
+	})
+
+	w := performRequest(router)
+	assert.Equal(t, "<p>/</p>", w.Body.String())
+}
+
+func TestAddTrustedPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		New().AddTrustedFromFiles("missing", "tests/missing.html")
+	})
+}
+
+func TestAddText(t *testing.T) {
+	for _, r := range []testRenderer{New(WithContentType("application/xhtml+xml")), NewDynamic()} {
+		r.AddFromString("page", "<p>{{.}}</p>")
+		r.AddTextFromString("mail", "Hello {{.}} & welcome")
+		r.AddTextFromFiles("files", "tests/base.html", "tests/article.html")
+
+		router := gin.New()
+		router.HTMLRender = r
+		router.GET("/", func(c *gin.Context) {
+			c.HTML(200, "mail", "<Gin>")
+		})
+
+		w := performRequest(router)
+		assert.Equal(t, 200, w.Code)
+		assert.Equal(t, "Hello <Gin> & welcome", w.Body.String())
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+		w, err := renderResponse(r, "files", gin.H{"title": "<Title>"})
+		assert.NoError(t, err)
+		assert.Equal(t, "<p><Title></p>\nHi, this is article template\n", w.Body.String())
+
+		w, err = renderResponse(r, "page", "<Gin>")
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>&lt;Gin&gt;</p>", w.Body.String())
+	}
+}
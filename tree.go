@@ -0,0 +1,47 @@
+package multitemplate
+
+import (
+	"html/template"
+	"text/template/parse"
+)
+
+// parseTree builds the template of a tree builder from a copy of its tree,
+// as html/template rewrites the tree it escapes.
+func (tb templateBuilder) parseTree() (*template.Template, error) {
+	return tb.newTemplate(tb.templateName).AddParseTree(tb.templateName, tb.tree.Copy())
+}
+
+// AddTree supply add template from a parse tree, e.g. built programmatically
+func (r Render) AddTree(name string, tree *parse.Tree) (*template.Template, error) {
+	builder := newTreeBuilder(name, tree)
+	builder.settings = &r.registry().opts
+	if err := builder.keep(); err != nil {
+		return nil, err
+	}
+	r.register(name, builder)
+	return builder.tmpl, nil
+}
+
+// AddTree supply add template from a parse tree, e.g. built programmatically
+func (r DynamicRender) AddTree(name string, tree *parse.Tree) (*template.Template, error) {
+	builder := newTreeBuilder(name, tree)
+	builder.settings = &r.registry().opts
+	tmpl, err := builder.build()
+	if err != nil {
+		return nil, err
+	}
+	r.register(name, builder)
+	return tmpl, nil
+}
+
+func newTreeBuilder(name string, tree *parse.Tree) *templateBuilder {
+	if tree == nil {
+		panic("template tree cannot be nil")
+	}
+	return &templateBuilder{
+		buildType:    treeTemplateType,
+		templateName: name,
+		tree:         tree,
+		options:      *NewTemplateOptions(),
+	}
+}
@@ -0,0 +1,28 @@
+package multitemplate
+
+import (
+	"testing"
+	"text/template/parse"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddTree(t *testing.T) {
+	trees, err := parse.Parse("index", "<p>Welcome to {{ .name }}</p>", "{{", "}}", nil)
+	assert.NoError(t, err)
+
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		tmpl, err := r.AddTree("index", trees["index"])
+		assert.NoError(t, err)
+		assert.Equal(t, "index", tmpl.Name())
+
+		for i := 0; i < 2; i++ {
+			w, err := renderResponse(r, "index", gin.H{"name": "<tree>"})
+			assert.NoError(t, err)
+			assert.Equal(t, "<p>Welcome to &lt;tree&gt;</p>", w.Body.String())
+		}
+	}
+
+	assert.Panics(t, func() { _, _ = New().AddTree("nil", nil) })
+}
@@ -0,0 +1,51 @@
+package multitemplate
+
+import (
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// builtinFuncs are the functions predefined by text/template and html/template.
+var builtinFuncs = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "slice": true,
+	"js": true, "len": true, "not": true, "or": true, "print": true,
+	"printf": true, "println": true, "urlquery": true,
+	"eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+	// predefined by this package, see predefinedFuncs
+	"await": true, "listRows": true,
+}
+
+// calledFuncs returns the sorted names of the functions called by the
+// templates of trees, including builtin ones.
+func calledFuncs(trees map[string]*parse.Tree) []string {
+	called := make(map[string]bool)
+	for _, tree := range trees {
+		if tree == nil {
+			continue
+		}
+		walkNodes(tree.Root, func(node parse.Node) {
+			if ident, ok := node.(*parse.IdentifierNode); ok {
+				called[ident.Ident] = true
+			}
+		})
+	}
+	return sortedNames(called)
+}
+
+// UsedFuncs implements Render.UsedFuncs and DynamicRender.UsedFuncs.
+func (r *registry) UsedFuncs(name string) ([]string, error) {
+	tmpl, err := r.build(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var used []string
+	for _, fn := range calledFuncs(templateTrees(tmpl)) {
+		if !builtinFuncs[fn] && !strings.HasPrefix(fn, "_html_template_") && !strings.HasPrefix(fn, "_multitemplate_") {
+			used = append(used, fn)
+		}
+	}
+	sort.Strings(used)
+	return used, nil
+}
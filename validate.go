@@ -0,0 +1,278 @@
+package multitemplate
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// Validate implements Render.Validate and DynamicRender.Validate.
+func (r *registry) Validate() error {
+	var errs []error
+	for _, name := range r.Names() {
+		for _, err := range r.validate(name) {
+			errs = append(errs, fmt.Errorf("template %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateReport implements Render.ValidateReport and DynamicRender.ValidateReport.
+func (r *registry) ValidateReport() (ok bool, report string) {
+	names := r.Names()
+	var b strings.Builder
+	failed := 0
+	for _, name := range names {
+		errs := r.validate(name)
+		if len(errs) == 0 {
+			continue
+		}
+		failed++
+		fmt.Fprintf(&b, "FAIL %s\n", name)
+		for _, err := range errs {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Fprintf(&b, "    %s\n", line)
+			}
+		}
+	}
+	if failed == 0 {
+		return true, fmt.Sprintf("all %d templates are valid\n", len(names))
+	}
+	return false, fmt.Sprintf("%d of %d templates failed validation:\n%s", failed, len(names), b.String())
+}
+
+// validate builds the template name and returns its build error or its
+// undefined template references.
+func (r *registry) validate(name string) []error {
+	tmpl, err := r.build(name)
+	if errors.Is(err, ErrTemplateNotFound) {
+		return nil
+	}
+	if err != nil {
+		return []error{err}
+	}
+	var errs []error
+	for _, undefined := range undefinedReferences(tmpl) {
+		errs = append(errs, errors.New(undefined))
+	}
+	for _, cycle := range includeCycles(tmpl) {
+		errs = append(errs, errors.New(cycle))
+	}
+	return errs
+}
+
+// undefinedReferences returns a description of every template reference of
+// the set of tmpl that does not resolve to a defined template, sorted.
+func undefinedReferences(tmpl executor) []string {
+	trees := templateTrees(tmpl)
+	var undefined []string
+	for _, ref := range templateReferences(trees) {
+		if t, ok := trees[ref.target]; !ok || t == nil || t.Root == nil {
+			undefined = append(undefined, ref.String())
+		}
+	}
+	sort.Strings(undefined)
+	return undefined
+}
+
+// templateReference is a {{template}} or {{block}} action of the template
+// from executing the template target.
+type templateReference struct {
+	from, target string
+}
+
+func (ref templateReference) String() string {
+	return fmt.Sprintf("undefined template %q referenced in %s", ref.target, ref.from)
+}
+
+// templateReferences returns every template reference of trees.
+func templateReferences(trees map[string]*parse.Tree) []templateReference {
+	var refs []templateReference
+	for name, tree := range trees {
+		if tree == nil || tree.Root == nil {
+			continue
+		}
+		walkNodes(tree.Root, func(node parse.Node) {
+			if ref, ok := node.(*parse.TemplateNode); ok {
+				refs = append(refs, templateReference{from: name, target: ref.Name})
+			}
+		})
+	}
+	return refs
+}
+
+// ValidateIncludes implements Render.ValidateIncludes and DynamicRender.ValidateIncludes.
+func (r *registry) ValidateIncludes() error {
+	defined := make(map[string]bool)
+	refs := make(map[string][]templateReference)
+	var errs []error
+	for _, name := range r.Names() {
+		tmpl, err := r.build(name)
+		if errors.Is(err, ErrTemplateNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", name, err))
+			continue
+		}
+		trees := templateTrees(tmpl)
+		for defName, tree := range trees {
+			if tree != nil && tree.Root != nil {
+				defined[defName] = true
+			}
+		}
+		refs[name] = templateReferences(trees)
+	}
+
+	for _, name := range sortedNames(refs) {
+		var dangling []string
+		for _, ref := range refs[name] {
+			if !defined[ref.target] {
+				dangling = append(dangling, ref.String())
+			}
+		}
+		sort.Strings(dangling)
+		for _, undefined := range dangling {
+			errs = append(errs, fmt.Errorf("template %s: %s", name, undefined))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// includeCycles returns a description of every cycle of unconditional
+// template includes in the set of tmpl, e.g. "a" including "b" including
+// "a", which recurses until the stack overflows when executed. Includes
+// inside if, range and with actions are ignored, as they may stop the
+// recursion, e.g. when rendering a tree.
+func includeCycles(tmpl executor) []string {
+	trees := templateTrees(tmpl)
+	includes := make(map[string][]string, len(trees))
+	for name, tree := range trees {
+		if tree == nil || tree.Root == nil {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, node := range tree.Root.Nodes {
+			if ref, ok := node.(*parse.TemplateNode); ok && !seen[ref.Name] {
+				seen[ref.Name] = true
+				includes[name] = append(includes[name], ref.Name)
+			}
+		}
+		sort.Strings(includes[name])
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(includes))
+	found := make(map[string]bool)
+	var cycles []string
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, ref := range includes[name] {
+			switch state[ref] {
+			case unvisited:
+				visit(ref)
+			case visiting:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] != ref {
+						continue
+					}
+					cycle := append(append([]string(nil), stack[i:]...), ref)
+					if desc := strings.Join(cycle, `" includes "`); !found[desc] {
+						found[desc] = true
+						cycles = append(cycles, fmt.Sprintf(`include cycle "%s"`, desc))
+					}
+					break
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+	for _, name := range sortedNames(includes) {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return cycles
+}
+
+// templateTrees returns the parse trees of the templates in the set of tmpl,
+// keyed by name. Engine templates have none.
+func templateTrees(tmpl executor) map[string]*parse.Tree {
+	trees := make(map[string]*parse.Tree)
+	switch t := tmpl.(type) {
+	case *template.Template:
+		for _, defined := range t.Templates() {
+			trees[defined.Name()] = defined.Tree
+		}
+	case *texttemplate.Template:
+		for _, defined := range t.Templates() {
+			trees[defined.Name()] = defined.Tree
+		}
+	}
+	return trees
+}
+
+// walkNodes calls fn for node and every node below it.
+func walkNodes(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, child := range n.Nodes {
+			walkNodes(child, fn)
+		}
+		return
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		fn(n)
+		for _, cmd := range n.Cmds {
+			walkNodes(cmd, fn)
+		}
+		return
+	}
+
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkNodes(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkNodes(n.Node, fn)
+	case *parse.TemplateNode:
+		walkNodes(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, fn)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	walkNodes(n.Pipe, fn)
+	walkNodes(n.List, fn)
+	walkNodes(n.ElseList, fn)
+}
@@ -0,0 +1,110 @@
+package multitemplate
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromFiles("index", "tests/base.html", "tests/article.html")
+	r.AddFromString("block", `{{block "content" .}}default{{end}}`)
+	assert.NoError(t, r.Validate())
+
+	r.AddFromString("typo", `{{if .}}{{template "heade" .}}{{else}}{{range .}}{{template "other"}}{{end}}{{end}}`)
+	r.AddTrustedFromString("trusted", `{{with .}}{{template "missing"}}{{end}}`)
+	r["broken"] = &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   "broken",
+		templateString: "{{ .name ",
+	}
+
+	err := r.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "template broken: ")
+	assert.Contains(t, err.Error(), `template trusted: undefined template "missing" referenced in trusted`)
+	assert.Contains(t, err.Error(), `template typo: undefined template "other" referenced in typo`)
+	assert.Contains(t, err.Error(), `template typo: undefined template "heade" referenced in typo`)
+}
+
+func TestValidateReport(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromString("index", "Welcome")
+	ok, report := r.ValidateReport()
+	assert.True(t, ok)
+	assert.Equal(t, "all 1 templates are valid\n", report)
+
+	r.AddFromString("typo", `{{template "heade" .}}{{template "other"}}`)
+	r["broken"] = &templateBuilder{
+		buildType:      stringTemplateType,
+		templateName:   "broken",
+		templateString: "{{ .name ",
+	}
+
+	ok, report = r.ValidateReport()
+	assert.False(t, ok)
+	assert.Equal(t, "2 of 3 templates failed validation:\n"+
+		"FAIL broken\n"+
+		"    template: broken:1: unclosed action\n"+
+		"FAIL typo\n"+
+		"    undefined template \"heade\" referenced in typo\n"+
+		"    undefined template \"other\" referenced in typo\n", report)
+}
+
+func TestValidateIncludeCycles(t *testing.T) {
+	r := NewDynamic()
+	r.AddFromString("tree", `{{define "node"}}{{.Name}}{{range .Children}}{{template "node" .}}{{end}}{{end}}{{template "node" .}}`)
+	assert.NoError(t, r.Validate())
+
+	r.AddFromString("self", `{{define "a"}}a{{template "a"}}{{end}}{{template "a"}}`)
+	r.AddFromString("cross", `{{define "a"}}{{template "b"}}{{end}}{{define "b"}}{{template "c"}}{{end}}`+
+		`{{define "c"}}{{template "a"}}{{end}}{{template "a"}}`)
+
+	err := r.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `template self: include cycle "a" includes "a"`)
+	assert.Contains(t, err.Error(), `template cross: include cycle "a" includes "b" includes "c" includes "a"`)
+	assert.NotContains(t, err.Error(), "template tree")
+}
+
+func TestDryRun(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		shared := template.Must(template.New("shared").Parse("{{ .name }}"))
+		r.AddFromString("index", "Welcome to {{ .name }} template")
+		r.AddTrustedFromString("trusted", "{{ .name }}")
+		r.Add("shared", shared)
+
+		assert.NoError(t, r.DryRun("index", gin.H{"name": "index"}))
+		err := r.DryRun("index", gin.H{"title": "index"})
+		assert.ErrorContains(t, err, `map has no entry for key "name"`)
+		assert.ErrorContains(t, r.DryRun("index", struct{ Title string }{}), "can't evaluate field name")
+		assert.ErrorContains(t, r.DryRun("trusted", gin.H{}), `map has no entry for key "name"`)
+		assert.ErrorContains(t, r.DryRun("shared", gin.H{}), `map has no entry for key "name"`)
+		assert.ErrorIs(t, r.DryRun("missing", nil), ErrTemplateNotFound)
+
+		w, err := renderResponse(r, "index", gin.H{})
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to  template", w.Body.String())
+		_, err = renderResponse(r, "shared", gin.H{})
+		assert.NoError(t, err)
+		assert.EqualError(t, r.DryRun("shared", gin.H{}), `html/template: cannot Clone "shared" after it has executed`)
+	}
+}
+
+func TestValidateIncludes(t *testing.T) {
+	for _, r := range []testRenderer{New(), NewDynamic()} {
+		r.AddFromString("partials", `{{define "header"}}Header{{end}}{{define "footer"}}Footer{{end}}`)
+		r.AddFromString("page", `{{template "header"}}{{block "content" .}}Content{{end}}{{template "footer"}}`)
+		assert.NoError(t, r.ValidateIncludes())
+		assert.Error(t, r.Validate())
+
+		r.AddFromString("typo", `{{if .}}{{template "heade"}}{{end}}{{template "footer"}}`)
+		r.AddTrustedFromString("trusted", `{{template "missing"}}`)
+		err := r.ValidateIncludes()
+		assert.EqualError(t, err, `template trusted: undefined template "missing" referenced in trusted`+"\n"+
+			`template typo: undefined template "heade" referenced in typo`)
+	}
+}
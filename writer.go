@@ -0,0 +1,43 @@
+package multitemplate
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// writerRender writes the body of the wrapped render through the writer
+// returned by wrap, see WithWriterWrapper.
+type writerRender struct {
+	wrapped render.Render
+	wrap    func(w io.Writer) io.Writer
+}
+
+// wrappedResponseWriter writes the body to w instead of the response.
+type wrappedResponseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (w wrappedResponseWriter) Write(b []byte) (int, error) {
+	return w.w.Write(b)
+}
+
+// Render (writerRender) writes the wrapped render through the wrapping writer.
+func (r writerRender) Render(w http.ResponseWriter) error {
+	return r.wrapped.Render(wrappedResponseWriter{ResponseWriter: w, w: r.wrap(w)})
+}
+
+// WriteContentType (writerRender) writes the ContentType of the wrapped render.
+func (r writerRender) WriteContentType(w http.ResponseWriter) {
+	r.wrapped.WriteContentType(w)
+}
+
+// wrapWriter wraps rr to write through the writer wrapper of the renderer, if any.
+func (r *registry) wrapWriter(rr render.Render) render.Render {
+	if r.opts.writerWrapper == nil {
+		return rr
+	}
+	return writerRender{wrapped: rr, wrap: r.opts.writerWrapper}
+}
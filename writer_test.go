@@ -0,0 +1,47 @@
+package multitemplate
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// byteCounter counts the bytes written through it.
+type byteCounter struct {
+	w      io.Writer
+	writes int
+	bytes  int
+}
+
+func (c *byteCounter) Write(b []byte) (int, error) {
+	c.writes++
+	c.bytes += len(b)
+	return c.w.Write(b)
+}
+
+func TestWithWriterWrapper(t *testing.T) {
+	for _, tt := range []struct {
+		opts   []RendererOption
+		writes int
+	}{
+		{nil, 3},
+		{[]RendererOption{WithWriteBuffer(1024)}, 1},
+		{[]RendererOption{WithPrettyHTML()}, 1},
+	} {
+		var counter *byteCounter
+		opts := append(tt.opts, WithWriterWrapper(func(w io.Writer) io.Writer {
+			counter = &byteCounter{w: w}
+			return counter
+		}))
+		r := New(opts...)
+		r.AddFromString("index", "<p>{{ .name }}</p>")
+
+		w := httptest.NewRecorder()
+		assert.NoError(t, r.Instance("index", gin.H{"name": "gin"}).Render(w))
+		assert.Equal(t, w.Body.Len(), counter.bytes)
+		assert.Equal(t, tt.writes, counter.writes)
+	}
+}
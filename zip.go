@@ -0,0 +1,57 @@
+package multitemplate
+
+import (
+	"archive/zip"
+	"html/template"
+)
+
+// zipSources opens the archive of a zip builder and reads its sources like
+// an fs.FS builder would. The archive is opened on every build, so
+// rebuilding the template picks up a replaced archive.
+func (tb templateBuilder) zipSources() ([]templateSource, error) {
+	archive, err := zip.OpenReader(tb.zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	fsBuilder := tb
+	fsBuilder.buildType = fsTemplateType
+	fsBuilder.fsys = archive
+	sources, err := fsBuilder.sources()
+	for i := range sources {
+		sources[i].fsys = nil
+	}
+	return sources, err
+}
+
+// AddFromZip supply add template from files (fs.Glob patterns) in the zip
+// archive at zipPath, named like the files of AddFromFS. The archive is read
+// again on every rebuild, so after replacing it ReloadAll, or every render of
+// a dynamic renderer, uses its new content.
+func (r Render) AddFromZip(name, zipPath string, files ...string) *template.Template {
+	return r.add(name, &templateBuilder{
+		buildType:    zipTemplateType,
+		templateName: name,
+		zipPath:      zipPath,
+		files:        files,
+		options:      *NewTemplateOptions(),
+	})
+}
+
+// AddFromZipGlob supply add template from files in a zip archive matching pattern, see AddFromZip
+func (r Render) AddFromZipGlob(name, zipPath, pattern string) *template.Template {
+	return r.AddFromZip(name, zipPath, pattern)
+}
+
+// AddFromZip supply add template from files in a zip archive, see Render.AddFromZip
+func (r DynamicRender) AddFromZip(name, zipPath string, files ...string) *template.Template {
+	builder := &templateBuilder{templateName: name, zipPath: zipPath, files: files, options: *NewTemplateOptions()}
+	builder.buildType = zipTemplateType
+	return r.add(name, builder)
+}
+
+// AddFromZipGlob supply add template from files in a zip archive matching pattern, see Render.AddFromZip
+func (r DynamicRender) AddFromZipGlob(name, zipPath, pattern string) *template.Template {
+	return r.AddFromZip(name, zipPath, pattern)
+}
@@ -0,0 +1,58 @@
+package multitemplate
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+}
+
+func TestAddFromZip(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "templates.zip")
+	writeZip(t, archive, map[string]string{
+		"templates/base.html":    `<p>{{template "content" .}}</p>`,
+		"templates/article.html": `{{define "content"}}Hi {{.name}}{{end}}`,
+	})
+
+	r := New()
+	r.AddFromZip("index", archive, "templates/base.html", "templates/article.html")
+	r.AddFromZipGlob("glob", archive, "templates/*.html")
+
+	rec, err := renderResponse(r, "index", gin.H{"name": "zip"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>Hi zip</p>", rec.Body.String())
+
+	names, err := r.DefinedTemplates("glob")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"article.html", "base.html", "content"}, names)
+	assert.Equal(t, []string{"glob", "index"}, r.TemplatesForFile(archive))
+
+	writeZip(t, archive, map[string]string{
+		"templates/base.html":    `<div>{{template "content" .}}</div>`,
+		"templates/article.html": `{{define "content"}}Bye {{.name}}{{end}}`,
+	})
+	assert.NoError(t, r.ReloadAll())
+	rec, err = renderResponse(r, "index", gin.H{"name": "zip"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<div>Bye zip</div>", rec.Body.String())
+
+	assert.Panics(t, func() { NewDynamic().AddFromZip("missing", filepath.Join(t.TempDir(), "missing.zip"), "a.html") })
+}